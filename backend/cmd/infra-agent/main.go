@@ -1,25 +1,49 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	httpadapter "github.com/isaacwallace123/portfolio-infra/internal/adapters/in/http"
+	alertmanageradapter "github.com/isaacwallace123/portfolio-infra/internal/adapters/out/alertmanager"
+	boltadapter "github.com/isaacwallace123/portfolio-infra/internal/adapters/out/bolt"
+	"github.com/isaacwallace123/portfolio-infra/internal/adapters/out/httpclient"
 	k8sadapter "github.com/isaacwallace123/portfolio-infra/internal/adapters/out/kubernetes"
+	lokiadapter "github.com/isaacwallace123/portfolio-infra/internal/adapters/out/loki"
+	metricsserveradapter "github.com/isaacwallace123/portfolio-infra/internal/adapters/out/metricsserver"
+	notifyadapter "github.com/isaacwallace123/portfolio-infra/internal/adapters/out/notify"
 	overwatchadapter "github.com/isaacwallace123/portfolio-infra/internal/adapters/out/overwatch"
 	prometheusadapter "github.com/isaacwallace123/portfolio-infra/internal/adapters/out/prometheus"
+	"github.com/isaacwallace123/portfolio-infra/internal/adapters/out/resilience"
+	rulesadapter "github.com/isaacwallace123/portfolio-infra/internal/adapters/out/rules"
+	portout "github.com/isaacwallace123/portfolio-infra/internal/core/ports/out"
 	"github.com/isaacwallace123/portfolio-infra/internal/service"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	metricsv1beta1 "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
+const (
+	// backendTimeout bounds every request to Loki and Alertmanager.
+	backendTimeout = 30 * time.Second
+	// overwatchTimeout leaves room for a pod analysis, which Overwatch runs
+	// while the request is open.
+	overwatchTimeout = 3 * time.Minute
+)
+
 func main() {
 	apiKey := os.Getenv("INFRA_API_KEY")
+	adminKey := os.Getenv("INFRA_ADMIN_API_KEY")
 	promURL := strings.TrimRight(os.Getenv("PROMETHEUS_URL"), "/")
 	lokiURL := strings.TrimRight(os.Getenv("LOKI_URL"), "/")
 	overwatchURL := strings.TrimRight(os.Getenv("OVERWATCH_URL"), "/")
+	alertmanagerURL := strings.TrimRight(os.Getenv("ALERTMANAGER_URL"), "/")
+	catalogPath := os.Getenv("METRICS_CATALOG")
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -31,6 +55,13 @@ func main() {
 		log.Fatalf("Failed to load in-cluster config: %v", err)
 	}
 
+	policy := resilience.Policy{
+		Attempts:         envInt("BACKEND_RETRY_ATTEMPTS", 0),
+		FailureThreshold: envInt("BACKEND_BREAKER_THRESHOLD", 0),
+		Cooldown:         envDuration("BACKEND_BREAKER_COOLDOWN", 0),
+	}
+	config.WrapTransport = resilience.Middleware("kubernetes", policy)
+
 	k8sClient, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		log.Fatalf("Failed to create Kubernetes client: %v", err)
@@ -41,13 +72,74 @@ func main() {
 		log.Fatalf("Failed to create metrics client: %v", err)
 	}
 
-	clusterRepo := k8sadapter.NewKubernetesRepository(k8sClient, metricsClient, lokiURL)
-	metricsRepo := prometheusadapter.NewPrometheusRepository(promURL)
-	overwatchRepo := overwatchadapter.NewOverwatchRepository(overwatchURL)
-	infraSvc := service.NewInfraService(clusterRepo, metricsRepo, overwatchRepo)
+	catalog, err := prometheusadapter.LoadCatalog(catalogPath)
+	if err != nil {
+		log.Fatalf("Failed to load metric catalog: %v", err)
+	}
+
+	promHTTP := backendHTTP("PROMETHEUS", policy)
+	lokiClient := backendHTTP("LOKI", policy).Client(backendTimeout)
+	overwatchClient := backendHTTP("OVERWATCH", policy).Client(overwatchTimeout)
+	alertmanagerClient := backendHTTP("ALERTMANAGER", policy).Client(backendTimeout)
+
+	clusterRepo := k8sadapter.NewKubernetesRepository(k8sClient, metricsClient, lokiURL, lokiClient)
+	var metricsRepo portout.MetricsRepository
+	if promURL != "" {
+		metricsRepo = prometheusadapter.NewPrometheusRepository(promURL, catalog, prometheusadapter.Config{
+			MaxConcurrency: envInt("PROMETHEUS_MAX_CONCURRENCY", 0),
+			QueryTimeout:   envDuration("PROMETHEUS_QUERY_TIMEOUT", 0),
+			HTTP:           promHTTP,
+		})
+	} else {
+		log.Printf("PROMETHEUS_URL not set, sampling metrics-server instead")
+		metricsRepo = metricsserveradapter.NewMetricsServerRepository(metricsClient, k8sClient, metricsserveradapter.Config{
+			Interval:  envDuration("METRICS_SAMPLE_INTERVAL", 0),
+			Retention: envDuration("METRICS_SAMPLE_RETENTION", 0),
+		})
+	}
+	logMetricsRepo := lokiadapter.NewLokiRepository(lokiURL, lokiClient)
+	alertRepo := alertmanageradapter.NewAlertmanagerRepository(alertmanagerURL, alertmanagerClient)
+	var overwatchRepo portout.OverwatchRepository
+	if overwatchURL != "" {
+		overwatchRepo = overwatchadapter.NewOverwatchRepository(overwatchURL, overwatchClient)
+	} else {
+		log.Printf("OVERWATCH_URL not set, using the rule-based analyzer")
+		overwatchRepo = rulesadapter.NewOverwatchRepository(clusterRepo, metricsRepo, rulesadapter.Config{
+			RestartThreshold:    int32(envInt("RULES_RESTART_THRESHOLD", 0)),
+			DiskWarnPercent:     float64(envInt("RULES_DISK_WARN_PERCENT", 0)),
+			DiskCriticalPercent: float64(envInt("RULES_DISK_CRITICAL_PERCENT", 0)),
+		})
+	}
+	var insightStore portout.InsightStore
+	if path := os.Getenv("INSIGHT_STORE_PATH"); path != "" {
+		insightStore, err = boltadapter.NewInsightStore(path, envDuration("INSIGHT_STORE_RETENTION", 0))
+		if err != nil {
+			log.Fatalf("Failed to open insight store: %v", err)
+		}
+	}
+	infraSvc := service.NewInfraService(clusterRepo, metricsRepo, logMetricsRepo, alertRepo, overwatchRepo, insightStore)
+	if os.Getenv("INFRA_CACHE_DISABLED") != "true" {
+		ttls, err := service.ParseCacheTTLs(os.Getenv("INFRA_CACHE_TTLS"))
+		if err != nil {
+			log.Fatalf("Invalid INFRA_CACHE_TTLS: %v", err)
+		}
+		infraSvc = service.NewCachedInfraService(infraSvc, service.CacheConfig{
+			MaxEntries: envInt("INFRA_CACHE_MAX_ENTRIES", 0),
+			TTLs:       ttls,
+		})
+	}
+
+	if path := os.Getenv("NOTIFY_CONFIG"); path != "" {
+		sinks, rules, err := notifyadapter.LoadConfig(path, &http.Client{Timeout: backendTimeout})
+		if err != nil {
+			log.Fatalf("Failed to load notification config: %v", err)
+		}
+		watcher := service.NewNotificationWatcher(infraSvc, sinks, rules, envDuration("NOTIFY_INTERVAL", 0))
+		go watcher.Run(context.Background())
+	}
 
 	handler := httpadapter.NewHandler(infraSvc)
-	router := httpadapter.NewRouter(handler, apiKey)
+	router := httpadapter.NewRouter(handler, apiKey, adminKey)
 	server := httpadapter.NewServer(port, router)
 
 	log.Printf("Infra agent listening on :%s", port)
@@ -55,3 +147,36 @@ func main() {
 		log.Fatalf("Server failed: %v", err)
 	}
 }
+
+// backendHTTP loads the auth and TLS settings for one backend, see
+// httpclient.FromEnv for the variables read, and gives it its own retries and
+// circuit breaker.
+func backendHTTP(prefix string, policy resilience.Policy) *httpclient.Options {
+	opts, err := httpclient.FromEnv(prefix)
+	if err != nil {
+		log.Fatalf("Invalid %s client configuration: %v", prefix, err)
+	}
+	return opts.Wrap(resilience.Middleware(strings.ToLower(prefix), policy))
+}
+
+func envInt(key string, fallback int) int {
+	if v := os.Getenv(key); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			log.Fatalf("Invalid %s: %v", key, err)
+		}
+		return n
+	}
+	return fallback
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("Invalid %s: %v", key, err)
+		}
+		return d
+	}
+	return fallback
+}
@@ -4,10 +4,13 @@ go 1.25.0
 
 require (
 	github.com/docker/docker v27.5.1+incompatible
+	go.etcd.io/bbolt v1.4.3
+	golang.org/x/sync v0.19.0
 	k8s.io/api v0.35.2
 	k8s.io/apimachinery v0.35.2
 	k8s.io/client-go v0.35.2
 	k8s.io/metrics v0.35.2
+	sigs.k8s.io/yaml v1.6.0
 )
 
 require (
@@ -69,5 +72,4 @@ require (
 	sigs.k8s.io/json v0.0.0-20250730193827-2d320260d730 // indirect
 	sigs.k8s.io/randfill v1.0.0 // indirect
 	sigs.k8s.io/structured-merge-diff/v6 v6.3.0 // indirect
-	sigs.k8s.io/yaml v1.6.0 // indirect
 )
@@ -2,10 +2,14 @@ package httpadapter
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
 	portin "github.com/isaacwallace123/portfolio-infra/internal/core/ports/in"
 )
 
@@ -88,6 +92,32 @@ func (h *Handler) ContainerLogs(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, logs)
 }
 
+func (h *Handler) ContainerLogPatterns(w http.ResponseWriter, r *http.Request) {
+	id := extractPathParam(r.URL.Path, "/containers/", "/log-patterns")
+	if id == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "container ID required"})
+		return
+	}
+
+	tail := r.URL.Query().Get("tail")
+	if n, err := strconv.Atoi(tail); err != nil || n <= 0 {
+		tail = "1000"
+	} else if n > 5000 {
+		tail = "5000"
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	patterns, err := h.service.GetContainerLogPatterns(ctx, id, tail)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, patterns)
+}
+
 func (h *Handler) Networks(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
@@ -128,16 +158,17 @@ func (h *Handler) MetricsNode(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) MetricsRange(w http.ResponseWriter, r *http.Request) {
-	duration := r.URL.Query().Get("duration")
-	if duration == "" {
-		duration = "5m"
+	rng, err := parseTimeRange(r.URL.Query(), time.Now())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
 	}
 	containerName := r.URL.Query().Get("container")
 
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
 
-	data, err := h.service.GetMetricsRange(ctx, duration, containerName)
+	data, err := h.service.GetMetricsRange(ctx, rng, containerName)
 	if err != nil {
 		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
 		return
@@ -152,15 +183,222 @@ func (h *Handler) MetricsNodeRange(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "node parameter required"})
 		return
 	}
-	duration := r.URL.Query().Get("duration")
-	if duration == "" {
-		duration = "5m"
+	rng, err := parseTimeRange(r.URL.Query(), time.Now())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	data, err := h.service.GetNodeMetricsRange(ctx, node, rng)
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, data)
+}
+
+// MetricsNamespaces returns usage, requests and limits per namespace. Passing
+// duration or start adds CPU and memory series over that range.
+func (h *Handler) MetricsNamespaces(w http.ResponseWriter, r *http.Request) {
+	h.resourceUsage(w, r, h.service.GetNamespaceUsage)
+}
+
+// MetricsApps is MetricsNamespaces grouped by app within each namespace.
+func (h *Handler) MetricsApps(w http.ResponseWriter, r *http.Request) {
+	h.resourceUsage(w, r, h.service.GetAppUsage)
+}
+
+func (h *Handler) resourceUsage(w http.ResponseWriter, r *http.Request, get func(context.Context, *domain.TimeRange) (*domain.ResourceUsageReport, error)) {
+	var rng *domain.TimeRange
+	if q := r.URL.Query(); q.Has("duration") || q.Has("start") {
+		parsed, err := parseTimeRange(q, time.Now())
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		rng = &parsed
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 20*time.Second)
+	defer cancel()
+
+	data, err := get(ctx, rng)
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, data)
+}
+
+// MetricsTop ranks the heaviest consumers:
+//
+//	by      cpu, memory, network, diskio or restarts (default cpu)
+//	kind    pod, app or node (default pod)
+//	limit   number of entries, 1-100 (default 10)
+//	window  lookback for rates and restart counts, e.g. "5m", "1h" (default 5m)
+func (h *Handler) MetricsTop(w http.ResponseWriter, r *http.Request) {
+	q, err := parseTopQuery(r.URL.Query())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
 
-	data, err := h.service.GetNodeMetricsRange(ctx, node, duration)
+	data, err := h.service.GetTopConsumers(ctx, q)
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, data)
+}
+
+// MetricsHealth reports Prometheus target, rule and storage health. It answers
+// 503 when Prometheus could not be reached at all.
+func (h *Handler) MetricsHealth(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	health, err := h.service.GetMetricsHealth(ctx)
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusOK
+	if !health.Up {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, health)
+}
+
+// CacheStats reports hit/miss counts of the service response cache.
+func (h *Handler) CacheStats(w http.ResponseWriter, r *http.Request) {
+	reader, ok := h.service.(portin.CacheStatsReader)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "response cache disabled"})
+		return
+	}
+	writeJSON(w, http.StatusOK, reader.CacheStats())
+}
+
+// MetricsForecast projects when filesystems, PVCs and node memory fill up.
+// See parseForecastQuery for parameters.
+func (h *Handler) MetricsForecast(w http.ResponseWriter, r *http.Request) {
+	q, err := parseForecastQuery(r.URL.Query(), time.Now())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 20*time.Second)
+	defer cancel()
+
+	report, err := h.service.ForecastCapacity(ctx, q)
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+func (h *Handler) MetricsBreakdown(w http.ResponseWriter, r *http.Request) {
+	node := r.URL.Query().Get("node")
+	rng, err := parseTimeRange(r.URL.Query(), time.Now())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	data, err := h.service.GetMetricsBreakdown(ctx, node, rng)
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, data)
+}
+
+func (h *Handler) MetricsCatalog(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	defs, err := h.service.ListMetricDefinitions(ctx)
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, defs)
+}
+
+func (h *Handler) MetricsCustom(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	name := q.Get("name")
+	if name == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "name parameter required"})
+		return
+	}
+	scope := q.Get("scope")
+	if scope == "" {
+		scope = domain.MetricScopeHost
+	}
+	node := q.Get("node")
+	containerName := q.Get("container")
+	if scope == domain.MetricScopeNode && node == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "node parameter required for node scope"})
+		return
+	}
+	if scope == domain.MetricScopePod && containerName == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "container parameter required for pod scope"})
+		return
+	}
+	rng, err := parseTimeRange(q, time.Now())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	data, err := h.service.GetCatalogMetricRange(ctx, scope, name, node, containerName, rng)
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, data)
+}
+
+func (h *Handler) MetricsLogs(w http.ResponseWriter, r *http.Request) {
+	namespace := r.URL.Query().Get("namespace")
+	app := r.URL.Query().Get("app")
+	if namespace == "" && app == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "namespace or app required"})
+		return
+	}
+	rng, err := parseTimeRange(r.URL.Query(), time.Now())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	data, err := h.service.GetLogMetricsRange(ctx, rng, namespace, app)
 	if err != nil {
 		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
 		return
@@ -182,6 +420,108 @@ func (h *Handler) Dependencies(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, deps)
 }
 
+func (h *Handler) Alerts(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	groups, err := h.service.ListAlertGroups(ctx)
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, groups)
+}
+
+func (h *Handler) Silences(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	silences, err := h.service.ListSilences(ctx)
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, silences)
+}
+
+// CreateSilence accepts a silence as JSON. startsAt defaults to now and
+// createdBy to "infra-agent"; matchers, endsAt and comment are required.
+func (h *Handler) CreateSilence(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	var silence domain.Silence
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&silence); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+		return
+	}
+	if silence.StartsAt.IsZero() {
+		silence.StartsAt = time.Now()
+	}
+	if silence.CreatedBy == "" {
+		silence.CreatedBy = "infra-agent"
+	}
+	switch {
+	case len(silence.Matchers) == 0:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "at least one matcher is required"})
+		return
+	case !silence.EndsAt.After(silence.StartsAt):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "endsAt must be after startsAt"})
+		return
+	case strings.TrimSpace(silence.Comment) == "":
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "comment is required"})
+		return
+	}
+	for i, m := range silence.Matchers {
+		if m.Name == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "matcher name is required"})
+			return
+		}
+		if m.IsEqual == nil {
+			isEqual := true
+			silence.Matchers[i].IsEqual = &isEqual
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	id, err := h.service.CreateSilence(ctx, silence)
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]string{"id": id})
+}
+
+// ExpireSilence handles DELETE /admin/silences/{id}.
+func (h *Handler) ExpireSilence(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/admin/silences/")
+	if id == "" || strings.Contains(id, "/") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "silence ID required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.service.ExpireSilence(ctx, id); err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "expired"})
+}
+
 func (h *Handler) Nodes(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
@@ -196,7 +536,8 @@ func (h *Handler) Nodes(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) OverwatchInsights(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	// Long enough for the local detector to run when Overwatch is down.
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
 	insight, err := h.service.GetOverwatchInsights(ctx)
@@ -205,9 +546,173 @@ func (h *Handler) OverwatchInsights(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, insight)
+	if includeHidden(r) {
+		writeJSON(w, http.StatusOK, insight)
+		return
+	}
+	writeJSON(w, http.StatusOK, insight.WithoutHidden())
+}
+
+// includeHidden reports whether snoozed and muted anomalies were asked for
+// with ?hidden=true; by default they are left out.
+func includeHidden(r *http.Request) bool {
+	return r.URL.Query().Get("hidden") == "true"
+}
+
+// LocalAnomalies runs the built-in anomaly detector. It takes the usual time
+// range parameters, defaulting to the last two days at 10 minute resolution.
+func (h *Handler) LocalAnomalies(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if !q.Has("duration") && !q.Has("start") {
+		q = cloneValues(q)
+		q.Set("duration", defaultAnomalyLookback)
+		if !q.Has("step") {
+			q.Set("step", defaultAnomalyStep)
+		}
+	}
+	rng, err := parseTimeRange(q, time.Now())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	insight, err := h.service.DetectAnomalies(ctx, rng)
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if includeHidden(r) {
+		writeJSON(w, http.StatusOK, insight)
+		return
+	}
+	writeJSON(w, http.StatusOK, insight.WithoutHidden())
+}
+
+// AnomalyAcks lists acks in effect (GET) or acknowledges, snoozes or mutes an
+// anomaly fingerprint (POST). Snoozes need an until time in the future.
+func (h *Handler) AnomalyAcks(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		acks, err := h.service.ListAnomalyAcks(ctx)
+		if err != nil {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, acks)
+		return
+	case http.MethodPost:
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	var ack domain.AnomalyAck
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&ack); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+		return
+	}
+	if ack.State == "" {
+		ack.State = domain.AckAcknowledged
+	}
+	switch {
+	case strings.TrimSpace(ack.Fingerprint) == "":
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "fingerprint is required"})
+		return
+	case ack.State != domain.AckAcknowledged && ack.State != domain.AckSnoozed && ack.State != domain.AckMuted:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "state must be acknowledged, snoozed or muted"})
+		return
+	case ack.State == domain.AckSnoozed && ack.Until == nil:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "until is required to snooze"})
+		return
+	case ack.Until != nil && !ack.Until.After(time.Now()):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "until must be in the future"})
+		return
+	}
+
+	saved, err := h.service.AcknowledgeAnomaly(ctx, ack)
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusCreated, saved)
 }
 
+// DeleteAnomalyAck handles DELETE /admin/anomalies/acks/{fingerprint}.
+func (h *Handler) DeleteAnomalyAck(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	fingerprint := strings.TrimPrefix(r.URL.Path, "/admin/anomalies/acks/")
+	if fingerprint == "" || strings.Contains(fingerprint, "/") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "fingerprint required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	err := h.service.DeleteAnomalyAck(ctx, fingerprint)
+	if errors.Is(err, domain.ErrAckNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// EntityInsights handles GET /entities/{id}/insights, where id is an entity ID
+// such as "app:media/jellyfin". Snoozed and muted anomalies are left out
+// unless ?hidden=true.
+func (h *Handler) EntityInsights(w http.ResponseWriter, r *http.Request) {
+	id, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/entities/"), "/insights")
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return
+	}
+	if _, _, valid := domain.ParseEntityID(id); !valid {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid entity id"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	insights, err := h.service.GetEntityInsights(ctx, id)
+	if errors.Is(err, domain.ErrEntityNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+	if !includeHidden(r) {
+		visible := *insights
+		visible.Anomalies = make([]domain.EntityAnomaly, 0, len(insights.Anomalies))
+		for _, a := range insights.Anomalies {
+			if !a.Hidden {
+				visible.Anomalies = append(visible.Anomalies, a)
+			}
+		}
+		insights = &visible
+	}
+	writeJSON(w, http.StatusOK, insights)
+}
+
+// PodInsights returns the latest insight already recorded for an app. It
+// never runs an analysis; without an insight it answers 404 and the caller
+// should POST /pod-insights to start one.
 func (h *Handler) PodInsights(w http.ResponseWriter, r *http.Request) {
 	namespace := r.URL.Query().Get("namespace")
 	app := r.URL.Query().Get("app")
@@ -216,16 +721,88 @@ func (h *Handler) PodInsights(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Minute)
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	insight, err := h.service.GetPodInsights(ctx, namespace, app)
+	insights, err := h.service.GetAllPodInsights(ctx)
 	if err != nil {
 		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
 		return
 	}
+	for _, insight := range insights {
+		if insight.Namespace == namespace && insight.App == app {
+			writeJSON(w, http.StatusOK, insight)
+			return
+		}
+	}
+	writeJSON(w, http.StatusNotFound, map[string]string{"error": "no insight for " + namespace + "/" + app + " yet; POST /pod-insights to analyze it"})
+}
+
+// AnalyzePod queues an Overwatch analysis of one app and answers 202 with the
+// job to poll at /jobs/{id}. namespace and app come from the query string or
+// a JSON body.
+func (h *Handler) AnalyzePod(w http.ResponseWriter, r *http.Request) {
+	target := struct {
+		Namespace string `json:"namespace"`
+		App       string `json:"app"`
+	}{
+		Namespace: r.URL.Query().Get("namespace"),
+		App:       r.URL.Query().Get("app"),
+	}
+	if target.Namespace == "" && target.App == "" && r.ContentLength != 0 {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&target); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid body: " + err.Error()})
+			return
+		}
+	}
+	if target.Namespace == "" || target.App == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "namespace and app required"})
+		return
+	}
 
-	writeJSON(w, http.StatusOK, insight)
+	job, err := h.service.StartPodAnalysis(r.Context(), target.Namespace, target.App)
+	writeJob(w, job, err)
+}
+
+// RefreshCluster queues a refresh of the cluster insight. The agent can't
+// start a cluster-wide Overwatch analysis, which runs on Overwatch's own
+// schedule, so the job fetches and records the latest one; the rule-based
+// analyzer re-runs its rules.
+func (h *Handler) RefreshCluster(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	job, err := h.service.StartClusterRefresh(r.Context())
+	writeJob(w, job, err)
+}
+
+func writeJob(w http.ResponseWriter, job *domain.Job, err error) {
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func (h *Handler) Job(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/jobs/")
+	if id == "" || strings.Contains(id, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	job, err := h.service.GetJob(r.Context(), id)
+	if errors.Is(err, domain.ErrJobNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
 }
 
 func (h *Handler) AllPodInsights(w http.ResponseWriter, r *http.Request) {
@@ -241,17 +818,64 @@ func (h *Handler) AllPodInsights(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, insights)
 }
 
+// OverwatchHistory lists insights newest first; see parseHistoryQuery for
+// parameters. When more remain, X-Next-Cursor holds the cursor for the next page.
 func (h *Handler) OverwatchHistory(w http.ResponseWriter, r *http.Request) {
+	q, err := parseHistoryQuery(r.URL.Query())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	history, err := h.service.GetOverwatchHistory(ctx)
+	page, err := h.service.GetOverwatchHistory(ctx, q)
 	if err != nil {
 		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
 		return
 	}
 
-	writeJSON(w, http.StatusOK, history)
+	if page.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+	}
+	items := page.Items
+	if !includeHidden(r) {
+		items = make([]domain.OverwatchInsight, len(page.Items))
+		for i, insight := range page.Items {
+			items[i] = insight.WithoutHidden()
+		}
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+// OverwatchDiff compares two insights by anomaly fingerprint; see
+// parseDiffQuery for parameters. With neither set it shows what changed in
+// the last run.
+func (h *Handler) OverwatchDiff(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDiffQuery(r.URL.Query())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	diff, err := h.service.DiffOverwatchInsights(ctx, from, to)
+	if errors.Is(err, domain.ErrSnapshotNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+	if !includeHidden(r) {
+		visible := diff.WithoutHidden()
+		diff = &visible
+	}
+	writeJSON(w, http.StatusOK, diff)
 }
 
 func extractPathParam(path, prefix, suffix string) string {
@@ -27,6 +27,26 @@ func apiKeyMiddleware(apiKey string, next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// adminKeyMiddleware guards endpoints that change state elsewhere. They are
+// disabled entirely when no admin key is configured.
+func adminKeyMiddleware(adminKey string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if adminKey == "" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"error": "admin endpoints disabled"})
+			return
+		}
+		if r.Header.Get("X-Admin-Key") != adminKey {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "unauthorized"})
+			return
+		}
+		next(w, r)
+	}
+}
+
 func contentTypeMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,322 @@
+package httpadapter
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+const (
+	defaultDuration  = "5m"
+	defaultMaxPoints = 120
+	// maxPointsLimit matches Prometheus' hard limit of 11,000 points per series.
+	maxPointsLimit = 11000
+	minStep        = 10 * time.Second
+
+	defaultTopLimit  = 10
+	maxTopLimit      = 100
+	defaultTopWindow = 5 * time.Minute
+
+	defaultForecastLookback  = "7d"
+	defaultForecastHorizon   = 7 * 24 * time.Hour
+	defaultForecastThreshold = 100
+
+	defaultAnomalyLookback = "2d"
+	defaultAnomalyStep     = "10m"
+
+	defaultHistoryLimit = 48
+	maxHistoryLimit     = 500
+
+	// maxDuration bounds parsed durations well inside time.Duration's range.
+	maxDuration = 10 * 365 * 24 * time.Hour
+	// maxUnixSeconds is the last second of year 9999, the end of RFC3339.
+	maxUnixSeconds = 253402300799
+)
+
+// niceSteps are the resolutions picked by automatic step selection, so that
+// points line up on round timestamps.
+var niceSteps = []time.Duration{
+	10 * time.Second, 15 * time.Second, 30 * time.Second,
+	time.Minute, 2 * time.Minute, 5 * time.Minute, 10 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 2 * time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour, 24 * time.Hour,
+}
+
+// parseTimeRange resolves the time range query parameters shared by every
+// metrics endpoint:
+//
+//	duration   lookback from end, e.g. "5m", "24h", "7d", "30d" (default "5m")
+//	start, end RFC3339 or unix seconds; start overrides duration, end defaults to now
+//	step       resolution in whole seconds, e.g. "30s", "5m" or "30"; chosen automatically if omitted
+//	maxPoints  point budget for automatic step selection (default 120)
+func parseTimeRange(q url.Values, now time.Time) (domain.TimeRange, error) {
+	end := now
+	if v := q.Get("end"); v != "" {
+		t, err := parseTimestamp(v)
+		if err != nil {
+			return domain.TimeRange{}, fmt.Errorf("invalid end: %w", err)
+		}
+		end = t
+	}
+
+	var start time.Time
+	if v := q.Get("start"); v != "" {
+		t, err := parseTimestamp(v)
+		if err != nil {
+			return domain.TimeRange{}, fmt.Errorf("invalid start: %w", err)
+		}
+		start = t
+	} else {
+		duration := q.Get("duration")
+		if duration == "" {
+			duration = defaultDuration
+		}
+		d, err := parseDuration(duration)
+		if err != nil {
+			return domain.TimeRange{}, fmt.Errorf("invalid duration: %w", err)
+		}
+		start = end.Add(-d)
+	}
+
+	if !end.After(start) {
+		return domain.TimeRange{}, fmt.Errorf("end must be after start")
+	}
+	span := end.Sub(start)
+
+	maxPoints := defaultMaxPoints
+	if v := q.Get("maxPoints"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPointsLimit {
+			return domain.TimeRange{}, fmt.Errorf("maxPoints must be between 1 and %d", maxPointsLimit)
+		}
+		maxPoints = n
+	}
+
+	var step time.Duration
+	if v := q.Get("step"); v != "" {
+		d, err := parseDuration(v)
+		if err != nil {
+			return domain.TimeRange{}, fmt.Errorf("invalid step: %w", err)
+		}
+		if d < time.Second {
+			return domain.TimeRange{}, fmt.Errorf("step must be at least 1s")
+		}
+		// Backends take steps in whole seconds.
+		if d%time.Second != 0 {
+			return domain.TimeRange{}, fmt.Errorf("step must be a whole number of seconds")
+		}
+		if span/d > maxPointsLimit {
+			return domain.TimeRange{}, fmt.Errorf("step too small: range would exceed %d points", maxPointsLimit)
+		}
+		step = d
+	} else {
+		step = autoStep(span, maxPoints)
+	}
+
+	return domain.TimeRange{Start: start, End: end, Step: step}, nil
+}
+
+// autoStep picks the smallest nice step that keeps span/step within maxPoints.
+func autoStep(span time.Duration, maxPoints int) time.Duration {
+	raw := time.Duration(math.Ceil(float64(span) / float64(maxPoints)))
+	if raw <= minStep {
+		return minStep
+	}
+	for _, s := range niceSteps {
+		if s >= raw {
+			return s
+		}
+	}
+	days := (raw + 24*time.Hour - 1) / (24 * time.Hour)
+	return days * 24 * time.Hour
+}
+
+// parseDuration accepts Go durations plus day ("7d") and week ("2w") units,
+// and bare numbers as seconds, up to maxDuration.
+func parseDuration(s string) (time.Duration, error) {
+	invalid := fmt.Errorf("%q is not a valid duration", s)
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		// Also rejects NaN, which fails every comparison.
+		if !(secs > 0 && secs <= maxDuration.Seconds()) {
+			return 0, invalid
+		}
+		return time.Duration(secs * float64(time.Second)), nil
+	}
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.ParseInt(n, 10, 64)
+			if err != nil || v <= 0 || v > int64(maxDuration/unit) {
+				return 0, invalid
+			}
+			return time.Duration(v) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 || d > maxDuration {
+		return 0, invalid
+	}
+	return d, nil
+}
+
+// parseTimestamp accepts RFC3339 timestamps and unix seconds (optionally
+// fractional) between 0 and the end of year 9999.
+func parseTimestamp(s string) (time.Time, error) {
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		if !(secs >= 0 && secs <= maxUnixSeconds) {
+			return time.Time{}, fmt.Errorf("%q is out of range for unix seconds", s)
+		}
+		whole, frac := math.Modf(secs)
+		return time.Unix(int64(whole), int64(frac*1e9)), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither RFC3339 nor unix seconds", s)
+	}
+	return t, nil
+}
+
+// parseTopQuery resolves the /metrics/top parameters, applying defaults.
+func parseTopQuery(q url.Values) (domain.TopQuery, error) {
+	top := domain.TopQuery{
+		By:     domain.TopByCPU,
+		Kind:   domain.TopKindPod,
+		Limit:  defaultTopLimit,
+		Window: defaultTopWindow,
+	}
+
+	if v := q.Get("by"); v != "" {
+		switch v {
+		case domain.TopByCPU, domain.TopByMemory, domain.TopByNetwork, domain.TopByDiskIO, domain.TopByRestarts:
+			top.By = v
+		default:
+			return top, fmt.Errorf("by must be one of cpu, memory, network, diskio, restarts")
+		}
+	}
+	if v := q.Get("kind"); v != "" {
+		switch v {
+		case domain.TopKindPod, domain.TopKindApp, domain.TopKindNode:
+			top.Kind = v
+		default:
+			return top, fmt.Errorf("kind must be one of pod, app, node")
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxTopLimit {
+			return top, fmt.Errorf("limit must be between 1 and %d", maxTopLimit)
+		}
+		top.Limit = n
+	}
+	if v := q.Get("window"); v != "" {
+		d, err := parseDuration(v)
+		if err != nil {
+			return top, fmt.Errorf("invalid window: %w", err)
+		}
+		if d < time.Minute {
+			return top, fmt.Errorf("window must be at least 1m")
+		}
+		if d%time.Second != 0 {
+			return top, fmt.Errorf("window must be a whole number of seconds")
+		}
+		top.Window = d
+	}
+	return top, nil
+}
+
+// parseForecastQuery resolves the /metrics/forecast parameters: the usual time
+// range parameters (lookback defaults to 7d), horizon (default 7d) and
+// threshold, the usage percent counted as full (default 100).
+func parseForecastQuery(q url.Values, now time.Time) (domain.ForecastQuery, error) {
+	if !q.Has("duration") && !q.Has("start") {
+		q = cloneValues(q)
+		q.Set("duration", defaultForecastLookback)
+	}
+	rng, err := parseTimeRange(q, now)
+	if err != nil {
+		return domain.ForecastQuery{}, err
+	}
+
+	fq := domain.ForecastQuery{Range: rng, Horizon: defaultForecastHorizon, Threshold: defaultForecastThreshold}
+	if v := q.Get("horizon"); v != "" {
+		d, err := parseDuration(v)
+		if err != nil {
+			return fq, fmt.Errorf("invalid horizon: %w", err)
+		}
+		fq.Horizon = d
+	}
+	if v := q.Get("threshold"); v != "" {
+		t, err := strconv.ParseFloat(v, 64)
+		if err != nil || t <= 0 || t > 100 {
+			return fq, fmt.Errorf("threshold must be a percentage between 0 and 100")
+		}
+		fq.Threshold = t
+	}
+	return fq, nil
+}
+
+// parseHistoryQuery resolves the /history parameters: from and to (RFC3339 or
+// unix seconds, both optional), limit (default 48) and cursor, the
+// X-Next-Cursor of the previous page.
+func parseHistoryQuery(q url.Values) (domain.HistoryQuery, error) {
+	hq := domain.HistoryQuery{Limit: defaultHistoryLimit, Cursor: q.Get("cursor")}
+	if v := q.Get("from"); v != "" {
+		t, err := parseTimestamp(v)
+		if err != nil {
+			return hq, fmt.Errorf("invalid from: %w", err)
+		}
+		hq.From = t
+	}
+	if v := q.Get("to"); v != "" {
+		t, err := parseTimestamp(v)
+		if err != nil {
+			return hq, fmt.Errorf("invalid to: %w", err)
+		}
+		hq.To = t
+	}
+	if !hq.From.IsZero() && !hq.To.IsZero() && hq.To.Before(hq.From) {
+		return hq, fmt.Errorf("to must not be before from")
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxHistoryLimit {
+			return hq, fmt.Errorf("limit must be between 1 and %d", maxHistoryLimit)
+		}
+		hq.Limit = n
+	}
+	if _, err := hq.Upper(); err != nil {
+		return hq, err
+	}
+	return hq, nil
+}
+
+// parseDiffQuery resolves the /overwatch/diff parameters from and to (RFC3339
+// or unix seconds). Each selects the insight current at that time; without to
+// the newest is used, and without from the one before it.
+func parseDiffQuery(q url.Values) (from, to time.Time, err error) {
+	if v := q.Get("from"); v != "" {
+		if from, err = parseTimestamp(v); err != nil {
+			return from, to, fmt.Errorf("invalid from: %w", err)
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if to, err = parseTimestamp(v); err != nil {
+			return from, to, fmt.Errorf("invalid to: %w", err)
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && !to.After(from) {
+		return from, to, fmt.Errorf("to must be after from")
+	}
+	return from, to, nil
+}
+
+func cloneValues(q url.Values) url.Values {
+	c := make(url.Values, len(q))
+	for k, v := range q {
+		c[k] = append([]string(nil), v...)
+	}
+	return c
+}
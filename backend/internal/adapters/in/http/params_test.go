@@ -0,0 +1,131 @@
+package httpadapter
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "5m", want: 5 * time.Minute},
+		{in: "7d", want: 7 * 24 * time.Hour},
+		{in: "2w", want: 14 * 24 * time.Hour},
+		{in: "90", want: 90 * time.Second},
+		{in: "1.5", want: 1500 * time.Millisecond},
+		{in: "3650d", want: maxDuration},
+		{in: "3651d", wantErr: true},
+		{in: "600w", wantErr: true},
+		{in: "99999999999999d", wantErr: true},
+		{in: "1e300", wantErr: true},
+		{in: "100000h", wantErr: true},
+		{in: "0", wantErr: true},
+		{in: "-5", wantErr: true},
+		{in: "NaN", wantErr: true},
+		{in: "Inf", wantErr: true},
+		{in: "0d", wantErr: true},
+		{in: "-1h", wantErr: true},
+		{in: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseDuration(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseDuration(%q) = %s, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("parseDuration(%q) = %s, %v; want %s", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "1700000000", want: time.Unix(1_700_000_000, 0)},
+		{in: "1700000000.25", want: time.Unix(1_700_000_000, 250_000_000)},
+		{in: "2023-11-14T22:13:20Z", want: time.Unix(1_700_000_000, 0)},
+		{in: "NaN", wantErr: true},
+		{in: "Inf", wantErr: true},
+		{in: "-Inf", wantErr: true},
+		{in: "1e300", wantErr: true},
+		{in: "-1", wantErr: true},
+		{in: "yesterday", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseTimestamp(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseTimestamp(%q) = %s, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseTimestamp(%q) = %s, %v; want %s", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseTimeRangeStep(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	tests := []struct {
+		query   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{query: "duration=1h&step=30s", want: 30 * time.Second},
+		{query: "duration=1h&step=60", want: time.Minute},
+		{query: "duration=1h", want: 30 * time.Second},
+		{query: "duration=1h&step=1.5s", wantErr: true},
+		{query: "duration=1h&step=2.5", wantErr: true},
+		{query: "duration=1h&step=500ms", wantErr: true},
+		{query: "duration=30d&step=1s", wantErr: true},
+	}
+	for _, tt := range tests {
+		q, _ := url.ParseQuery(tt.query)
+		rng, err := parseTimeRange(q, now)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseTimeRange(%s) step = %s, want an error", tt.query, rng.Step)
+			}
+			continue
+		}
+		if err != nil || rng.Step != tt.want {
+			t.Errorf("parseTimeRange(%s) step = %s, %v; want %s", tt.query, rng.Step, err, tt.want)
+		}
+	}
+}
+
+func TestParseTopQueryWindow(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{query: "window=10m", want: 10 * time.Minute},
+		{query: "window=90.5", wantErr: true},
+		{query: "window=30s", wantErr: true},
+	}
+	for _, tt := range tests {
+		q, _ := url.ParseQuery(tt.query)
+		top, err := parseTopQuery(q)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseTopQuery(%s) window = %s, want an error", tt.query, top.Window)
+			}
+			continue
+		}
+		if err != nil || top.Window != tt.want {
+			t.Errorf("parseTopQuery(%s) window = %s, %v; want %s", tt.query, top.Window, err, tt.want)
+		}
+	}
+}
@@ -5,12 +5,20 @@ import (
 	"strings"
 )
 
-func NewRouter(h *Handler, apiKey string) http.Handler {
+func NewRouter(h *Handler, apiKey, adminKey string) http.Handler {
 	mux := http.NewServeMux()
+	stale := newStaleCache()
 
 	protected := func(hf http.HandlerFunc) http.HandlerFunc {
+		return contentTypeMiddleware(apiKeyMiddleware(apiKey, stale.middleware(hf)))
+	}
+	// live skips the stale cache for endpoints whose errors are the answer.
+	live := func(hf http.HandlerFunc) http.HandlerFunc {
 		return contentTypeMiddleware(apiKeyMiddleware(apiKey, hf))
 	}
+	admin := func(hf http.HandlerFunc) http.HandlerFunc {
+		return live(adminKeyMiddleware(adminKey, hf))
+	}
 
 	mux.HandleFunc("/health", contentTypeMiddleware(h.Health))
 	mux.HandleFunc("/containers", protected(h.Containers))
@@ -21,6 +29,8 @@ func NewRouter(h *Handler, apiKey string) http.Handler {
 			h.ContainerStats(w, r)
 		case strings.HasSuffix(path, "/logs"):
 			h.ContainerLogs(w, r)
+		case strings.HasSuffix(path, "/log-patterns"):
+			h.ContainerLogPatterns(w, r)
 		default:
 			http.NotFound(w, r)
 		}
@@ -30,12 +40,39 @@ func NewRouter(h *Handler, apiKey string) http.Handler {
 	mux.HandleFunc("/metrics/node", protected(h.MetricsNode))
 	mux.HandleFunc("/metrics/range", protected(h.MetricsRange))
 	mux.HandleFunc("/metrics/noderange", protected(h.MetricsNodeRange))
+	mux.HandleFunc("/metrics/breakdown", protected(h.MetricsBreakdown))
+	mux.HandleFunc("/metrics/namespaces", protected(h.MetricsNamespaces))
+	mux.HandleFunc("/metrics/apps", protected(h.MetricsApps))
+	mux.HandleFunc("/metrics/forecast", protected(h.MetricsForecast))
+	mux.HandleFunc("/metrics/health", live(h.MetricsHealth))
+	mux.HandleFunc("/metrics/top", protected(h.MetricsTop))
+	mux.HandleFunc("/metrics/catalog", protected(h.MetricsCatalog))
+	mux.HandleFunc("/metrics/custom", protected(h.MetricsCustom))
+	mux.HandleFunc("/metrics/logs", protected(h.MetricsLogs))
+	mux.HandleFunc("/alerts", protected(h.Alerts))
+	mux.HandleFunc("/alerts/silences", protected(h.Silences))
+	mux.HandleFunc("/admin/silences", admin(h.CreateSilence))
+	mux.HandleFunc("/admin/silences/", admin(h.ExpireSilence))
+	mux.HandleFunc("/admin/anomalies/acks", admin(h.AnomalyAcks))
+	mux.HandleFunc("/admin/anomalies/acks/", admin(h.DeleteAnomalyAck))
+	mux.HandleFunc("/cache/stats", live(h.CacheStats))
 	mux.HandleFunc("/dependencies", protected(h.Dependencies))
 	mux.HandleFunc("/nodes", protected(h.Nodes))
 	mux.HandleFunc("/overwatch/insights", protected(h.OverwatchInsights))
+	mux.HandleFunc("/overwatch/local", protected(h.LocalAnomalies))
+	mux.HandleFunc("/overwatch/diff", protected(h.OverwatchDiff))
 	mux.HandleFunc("/pod-insights/all", protected(h.AllPodInsights))
-	mux.HandleFunc("/pod-insights", protected(h.PodInsights))
+	mux.HandleFunc("/overwatch/refresh", protected(h.RefreshCluster))
+	mux.HandleFunc("/pod-insights", protected(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			h.AnalyzePod(w, r)
+			return
+		}
+		h.PodInsights(w, r)
+	}))
+	mux.HandleFunc("/jobs/", live(h.Job))
 	mux.HandleFunc("/history", protected(h.OverwatchHistory))
+	mux.HandleFunc("/entities/", protected(h.EntityInsights))
 
 	return loggingMiddleware(mux)
 }
@@ -0,0 +1,146 @@
+package httpadapter
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// staleMaxEntries bounds how many distinct URLs keep a last good response.
+	staleMaxEntries = 256
+	// staleMaxBody skips caching unusually large responses.
+	staleMaxBody = 2 << 20
+	// staleMaxAge is how old a response may be and still stand in for an error.
+	staleMaxAge = 24 * time.Hour
+)
+
+type staleEntry struct {
+	body   []byte
+	header http.Header
+	stored time.Time
+}
+
+// staleCache keeps the last successful response per GET URL so a backend
+// outage degrades to slightly old data instead of a 5xx.
+type staleCache struct {
+	mu      sync.Mutex
+	entries map[string]*staleEntry
+}
+
+func newStaleCache() *staleCache {
+	return &staleCache{entries: make(map[string]*staleEntry)}
+}
+
+func (c *staleCache) store(key string, header http.Header, body []byte) {
+	if len(body) > staleMaxBody {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= staleMaxEntries {
+		var oldestKey string
+		var oldest time.Time
+		for k, e := range c.entries {
+			if oldestKey == "" || e.stored.Before(oldest) {
+				oldestKey, oldest = k, e.stored
+			}
+		}
+		delete(c.entries, oldestKey)
+	}
+	c.entries[key] = &staleEntry{body: body, header: header.Clone(), stored: time.Now()}
+}
+
+func (c *staleCache) load(key string) (*staleEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || time.Since(e.stored) > staleMaxAge {
+		return nil, false
+	}
+	return e, true
+}
+
+// middleware buffers GET responses. Successful ones are remembered; server
+// errors are replaced by the remembered response. The X-Stale and Age headers
+// are the contract for telling a stale response apart and are set on every
+// shape; JSON objects also get "stale": true and "staleAgeSeconds" in the
+// body for convenience, since arrays have nowhere to carry them.
+func (c *staleCache) middleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next(w, r)
+			return
+		}
+
+		rec := &bufferedResponse{header: w.Header(), status: http.StatusOK}
+		next(rec, r)
+
+		key := r.URL.RequestURI()
+		switch {
+		case rec.status == http.StatusOK:
+			c.store(key, w.Header(), rec.body.Bytes())
+		case rec.status >= http.StatusInternalServerError:
+			if e, ok := c.load(key); ok {
+				age := time.Since(e.stored)
+				log.Printf("[infra] serving stale %s (age %s): HTTP %d", key, age.Round(time.Second), rec.status)
+				for name, values := range e.header {
+					w.Header()[name] = values
+				}
+				w.Header().Set("X-Stale", "true")
+				w.Header().Set("Age", strconv.Itoa(int(age.Seconds())))
+				w.Header().Del("Content-Length")
+				w.WriteHeader(http.StatusOK)
+				w.Write(markStale(e.body, age))
+				return
+			}
+		}
+
+		w.WriteHeader(rec.status)
+		w.Write(rec.body.Bytes())
+	}
+}
+
+// markStale adds the stale fields to a JSON object body. Other bodies (arrays,
+// non-JSON) are returned unchanged; X-Stale and Age mark them.
+func markStale(body []byte, age time.Duration) []byte {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return body
+	}
+	obj["stale"] = json.RawMessage("true")
+	obj["staleAgeSeconds"] = json.RawMessage(strconv.Itoa(int(age.Seconds())))
+	out, err := json.Marshal(obj)
+	if err != nil {
+		return body
+	}
+	return append(out, '\n')
+}
+
+// bufferedResponse holds a handler's response until the stale middleware
+// decides whether to send it.
+type bufferedResponse struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if !b.wroteHeader {
+		b.status, b.wroteHeader = status, true
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.wroteHeader = true
+	return b.body.Write(p)
+}
@@ -0,0 +1,215 @@
+package alertmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+	portout "github.com/isaacwallace123/portfolio-infra/internal/core/ports/out"
+)
+
+// nodeLabels are checked in order for the node an alert refers to.
+var nodeLabels = []string{"node", "kubernetes_node", "nodename"}
+
+type alertmanagerRepository struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewAlertmanagerRepository(baseURL string, client *http.Client) portout.AlertRepository {
+	return &alertmanagerRepository{
+		baseURL: baseURL,
+		client:  client,
+	}
+}
+
+// Alertmanager v2 API payloads.
+type alertGroup struct {
+	Labels   map[string]string `json:"labels"`
+	Receiver struct {
+		Name string `json:"name"`
+	} `json:"receiver"`
+	Alerts []gettableAlert `json:"alerts"`
+}
+
+type gettableAlert struct {
+	Fingerprint  string            `json:"fingerprint"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Status       struct {
+		State       string   `json:"state"`
+		SilencedBy  []string `json:"silencedBy"`
+		InhibitedBy []string `json:"inhibitedBy"`
+	} `json:"status"`
+}
+
+type gettableSilence struct {
+	ID     string `json:"id"`
+	Status struct {
+		State string `json:"state"`
+	} `json:"status"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	postableSilence
+}
+
+type postableSilence struct {
+	Matchers  []domain.SilenceMatcher `json:"matchers"`
+	StartsAt  time.Time               `json:"startsAt"`
+	EndsAt    time.Time               `json:"endsAt"`
+	CreatedBy string                  `json:"createdBy"`
+	Comment   string                  `json:"comment"`
+}
+
+func (r *alertmanagerRepository) do(ctx context.Context, method, path string, body, out interface{}) error {
+	if r.baseURL == "" {
+		return fmt.Errorf("alertmanager not configured")
+	}
+
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("alertmanager: encode request: %w", err)
+		}
+		reader = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, r.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("alertmanager: build request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("alertmanager: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("alertmanager: unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("alertmanager: decode response: %w", err)
+	}
+	return nil
+}
+
+// ListAlertGroups returns every alert group, including silenced and inhibited
+// alerts so callers can show their state.
+func (r *alertmanagerRepository) ListAlertGroups(ctx context.Context) ([]domain.AlertGroup, error) {
+	var groups []alertGroup
+	if err := r.do(ctx, http.MethodGet, "/api/v2/alerts/groups?silenced=true&inhibited=true&active=true", nil, &groups); err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.AlertGroup, 0, len(groups))
+	for _, g := range groups {
+		alerts := make([]domain.Alert, 0, len(g.Alerts))
+		for _, a := range g.Alerts {
+			alerts = append(alerts, toAlert(a))
+		}
+		result = append(result, domain.AlertGroup{
+			Labels:   g.Labels,
+			Receiver: g.Receiver.Name,
+			Alerts:   alerts,
+		})
+	}
+	return result, nil
+}
+
+func toAlert(a gettableAlert) domain.Alert {
+	alert := domain.Alert{
+		Fingerprint:  a.Fingerprint,
+		Name:         a.Labels["alertname"],
+		Severity:     a.Labels["severity"],
+		State:        a.Status.State,
+		Summary:      a.Annotations["summary"],
+		Description:  a.Annotations["description"],
+		Labels:       a.Labels,
+		Annotations:  a.Annotations,
+		StartsAt:     a.StartsAt,
+		EndsAt:       a.EndsAt,
+		GeneratorURL: a.GeneratorURL,
+		SilencedBy:   a.Status.SilencedBy,
+		InhibitedBy:  a.Status.InhibitedBy,
+	}
+	if alert.Description == "" {
+		alert.Description = a.Annotations["message"]
+	}
+	if alert.SilencedBy == nil {
+		alert.SilencedBy = []string{}
+	}
+	if alert.InhibitedBy == nil {
+		alert.InhibitedBy = []string{}
+	}
+	if ns, pod := a.Labels["namespace"], a.Labels["pod"]; ns != "" && pod != "" {
+		alert.ContainerID = ns + "/" + pod
+	}
+	for _, key := range nodeLabels {
+		if v := a.Labels[key]; v != "" {
+			alert.Node = v
+			break
+		}
+	}
+	return alert
+}
+
+func (r *alertmanagerRepository) ListSilences(ctx context.Context) ([]domain.Silence, error) {
+	var silences []gettableSilence
+	if err := r.do(ctx, http.MethodGet, "/api/v2/silences", nil, &silences); err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.Silence, 0, len(silences))
+	for _, s := range silences {
+		updated := s.UpdatedAt
+		result = append(result, domain.Silence{
+			ID:        s.ID,
+			State:     s.Status.State,
+			Matchers:  s.Matchers,
+			StartsAt:  s.StartsAt,
+			EndsAt:    s.EndsAt,
+			CreatedBy: s.CreatedBy,
+			Comment:   s.Comment,
+			UpdatedAt: &updated,
+		})
+	}
+	return result, nil
+}
+
+// CreateSilence posts a new silence and returns the ID Alertmanager assigned.
+func (r *alertmanagerRepository) CreateSilence(ctx context.Context, silence domain.Silence) (string, error) {
+	body := postableSilence{
+		Matchers:  silence.Matchers,
+		StartsAt:  silence.StartsAt,
+		EndsAt:    silence.EndsAt,
+		CreatedBy: silence.CreatedBy,
+		Comment:   silence.Comment,
+	}
+	var resp struct {
+		SilenceID string `json:"silenceID"`
+	}
+	if err := r.do(ctx, http.MethodPost, "/api/v2/silences", body, &resp); err != nil {
+		return "", err
+	}
+	return resp.SilenceID, nil
+}
+
+func (r *alertmanagerRepository) ExpireSilence(ctx context.Context, id string) error {
+	return r.do(ctx, http.MethodDelete, "/api/v2/silence/"+url.PathEscape(id), nil, nil)
+}
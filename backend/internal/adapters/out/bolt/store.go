@@ -0,0 +1,281 @@
+// Package bolt persists Overwatch insights in an embedded bbolt database.
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+	portout "github.com/isaacwallace123/portfolio-infra/internal/core/ports/out"
+)
+
+var (
+	insightsBucket    = []byte("insights")
+	podInsightsBucket = []byte("pod-insights")
+	acksBucket        = []byte("acks")
+)
+
+const (
+	defaultRetention = 30 * 24 * time.Hour
+	// pruneEvery limits how often writes also delete expired records.
+	pruneEvery = time.Hour
+)
+
+// Insights are keyed by the big-endian collection time, so bucket order is
+// time order. Pod insights are keyed by "namespace/app/" followed by the
+// analysis time, keeping each app's records together and sorted.
+type insightStore struct {
+	db        *bolt.DB
+	retention time.Duration
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+// NewInsightStore opens (creating if needed) the database at path. Records
+// older than retention are deleted; zero keeps 30 days.
+func NewInsightStore(path string, retention time.Duration) (portout.InsightStore, error) {
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open insight store %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{insightsBucket, podInsightsBucket, acksBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("init insight store: %w", err)
+	}
+
+	s := &insightStore{db: db, retention: retention}
+	s.prune()
+	return s, nil
+}
+
+func timeKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	return key
+}
+
+func keyTime(key []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(key[len(key)-8:])))
+}
+
+func podKey(namespace, app string, t time.Time) []byte {
+	return append([]byte(namespace+"/"+app+"/"), timeKey(t)...)
+}
+
+func (s *insightStore) SaveInsight(ctx context.Context, insight domain.OverwatchInsight) error {
+	if insight.CollectedAt == nil {
+		return nil
+	}
+	return s.putNew(insightsBucket, timeKey(*insight.CollectedAt), insight)
+}
+
+func (s *insightStore) SavePodInsight(ctx context.Context, insight domain.PodInsight) error {
+	if insight.AnalyzedAt.IsZero() {
+		return nil
+	}
+	return s.putNew(podInsightsBucket, podKey(insight.Namespace, insight.App, insight.AnalyzedAt), insight)
+}
+
+// putNew stores v unless key is already present. Insights don't change once
+// collected, and checking in a read transaction spares records seen before
+// a write transaction and its fsync.
+func (s *insightStore) putNew(bucket, key []byte, v any) error {
+	var exists bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		exists = tx.Bucket(bucket).Get(key) != nil
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("insight store: %w", err)
+	}
+	if exists {
+		return nil
+	}
+	return s.put(bucket, key, v)
+}
+
+func (s *insightStore) put(bucket, key []byte, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put(key, raw)
+	})
+	if err != nil {
+		return fmt.Errorf("insight store: %w", err)
+	}
+
+	s.mu.Lock()
+	due := time.Since(s.lastPruned) > pruneEvery
+	s.mu.Unlock()
+	if due {
+		s.prune()
+	}
+	return nil
+}
+
+func (s *insightStore) ListInsights(ctx context.Context, q domain.HistoryQuery) (*domain.HistoryPage, error) {
+	upper, err := q.Upper()
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.HistoryPage{Items: []domain.OverwatchInsight{}}
+	err = s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(insightsBucket).Cursor()
+
+		var k, v []byte
+		if upper.IsZero() {
+			k, v = c.Last()
+		} else {
+			// Seek lands on the first key after upper; step back onto it.
+			k, v = c.Seek(timeKey(upper.Add(time.Nanosecond)))
+			if k == nil {
+				k, v = c.Last()
+			} else {
+				k, v = c.Prev()
+			}
+		}
+
+		for ; k != nil; k, v = c.Prev() {
+			if !q.From.IsZero() && keyTime(k).Before(q.From) {
+				break
+			}
+			if q.Limit > 0 && len(page.Items) == q.Limit {
+				last := page.Items[len(page.Items)-1]
+				page.NextCursor = domain.HistoryCursor(*last.CollectedAt)
+				break
+			}
+			var insight domain.OverwatchInsight
+			if err := json.Unmarshal(v, &insight); err != nil {
+				log.Printf("[insight-store] skipping corrupt insight %x: %v", k, err)
+				continue
+			}
+			page.Items = append(page.Items, insight)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("insight store: %w", err)
+	}
+	return page, nil
+}
+
+func (s *insightStore) LatestPodInsights(ctx context.Context) ([]domain.PodInsight, error) {
+	insights := []domain.PodInsight{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(podInsightsBucket).Cursor()
+		var prefix []byte
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			// Walking backwards, the first key of each app is its newest.
+			p := k[:len(k)-8]
+			if bytes.Equal(p, prefix) {
+				continue
+			}
+			prefix = append(prefix[:0], p...)
+			var insight domain.PodInsight
+			if err := json.Unmarshal(v, &insight); err != nil {
+				log.Printf("[insight-store] skipping corrupt pod insight %q: %v", p, err)
+				continue
+			}
+			insights = append(insights, insight)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("insight store: %w", err)
+	}
+	return insights, nil
+}
+
+func (s *insightStore) SaveAck(ctx context.Context, ack domain.AnomalyAck) error {
+	return s.put(acksBucket, []byte(ack.Fingerprint), ack)
+}
+
+func (s *insightStore) ListAcks(ctx context.Context) ([]domain.AnomalyAck, error) {
+	acks := []domain.AnomalyAck{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(acksBucket).ForEach(func(k, v []byte) error {
+			var ack domain.AnomalyAck
+			if err := json.Unmarshal(v, &ack); err != nil {
+				log.Printf("[insight-store] skipping corrupt ack %q: %v", k, err)
+				return nil
+			}
+			acks = append(acks, ack)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("insight store: %w", err)
+	}
+	return acks, nil
+}
+
+func (s *insightStore) DeleteAck(ctx context.Context, fingerprint string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(acksBucket)
+		if b.Get([]byte(fingerprint)) == nil {
+			return domain.ErrAckNotFound
+		}
+		return b.Delete([]byte(fingerprint))
+	})
+}
+
+// prune deletes records older than the retention period. Acks are kept until
+// removed; lapsed ones are simply ignored.
+func (s *insightStore) prune() {
+	s.mu.Lock()
+	s.lastPruned = time.Now()
+	s.mu.Unlock()
+
+	cutoff := time.Now().Add(-s.retention)
+	removed := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{insightsBucket, podInsightsBucket} {
+			b := tx.Bucket(name)
+			// Deleting through a cursor mid-iteration skips keys; collect first.
+			var expired [][]byte
+			b.ForEach(func(k, _ []byte) error {
+				if len(k) < 8 || keyTime(k).Before(cutoff) {
+					expired = append(expired, append([]byte(nil), k...))
+				}
+				return nil
+			})
+			for _, k := range expired {
+				if err := b.Delete(k); err != nil {
+					return err
+				}
+			}
+			removed += len(expired)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("[insight-store] prune failed: %v", err)
+		return
+	}
+	if removed > 0 {
+		log.Printf("[insight-store] pruned %d records older than %s", removed, s.retention)
+	}
+}
@@ -0,0 +1,103 @@
+package bolt
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+func TestListInsightsPaging(t *testing.T) {
+	store, err := NewInsightStore(filepath.Join(t.TempDir(), "insights.db"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.(*insightStore).db.Close() })
+
+	// Recent enough that opening or writing never prunes them.
+	base := time.Now().Add(-time.Hour).Truncate(time.Minute)
+	ctx := context.Background()
+	for m := 1; m <= 5; m++ {
+		at := base.Add(time.Duration(m) * time.Minute)
+		if err := store.SaveInsight(ctx, domain.OverwatchInsight{CollectedAt: &at, Status: "healthy"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	minute := func(m int) time.Time { return base.Add(time.Duration(m) * time.Minute) }
+
+	tests := []struct {
+		name  string
+		q     domain.HistoryQuery
+		pages [][]int
+	}{
+		{name: "no limit", q: domain.HistoryQuery{}, pages: [][]int{{5, 4, 3, 2, 1}}},
+		{name: "pages of two", q: domain.HistoryQuery{Limit: 2}, pages: [][]int{{5, 4}, {3, 2}, {1}}},
+		{name: "exact fit has no cursor", q: domain.HistoryQuery{Limit: 5}, pages: [][]int{{5, 4, 3, 2, 1}}},
+		{name: "to on a key", q: domain.HistoryQuery{To: minute(4), Limit: 2}, pages: [][]int{{4, 3}, {2, 1}}},
+		{name: "to between keys", q: domain.HistoryQuery{To: minute(4).Add(-time.Second)}, pages: [][]int{{3, 2, 1}}},
+		{name: "to after every key", q: domain.HistoryQuery{To: minute(10), Limit: 3}, pages: [][]int{{5, 4, 3}, {2, 1}}},
+		{name: "from", q: domain.HistoryQuery{From: minute(3), Limit: 2}, pages: [][]int{{5, 4}, {3}}},
+		{name: "to before every key", q: domain.HistoryQuery{To: minute(0)}, pages: [][]int{{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.q
+			for i, want := range tt.pages {
+				page, err := store.ListInsights(ctx, q)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got := make([]int, len(page.Items))
+				for j, in := range page.Items {
+					got[j] = int(in.CollectedAt.Sub(base) / time.Minute)
+				}
+				if !slices.Equal(got, want) {
+					t.Fatalf("page %d = %v, want %v", i, got, want)
+				}
+				last := i == len(tt.pages)-1
+				if last != (page.NextCursor == "") {
+					t.Fatalf("page %d cursor = %q, last page = %v", i, page.NextCursor, last)
+				}
+				q.Cursor = page.NextCursor
+			}
+		})
+	}
+}
+
+func TestSaveKeepsStoredInsights(t *testing.T) {
+	store, err := NewInsightStore(filepath.Join(t.TempDir(), "insights.db"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.(*insightStore).db.Close() })
+	ctx := context.Background()
+
+	at := time.Now().Add(-time.Minute)
+	for _, status := range []string{"warning", "healthy"} {
+		if err := store.SaveInsight(ctx, domain.OverwatchInsight{CollectedAt: &at, Status: status}); err != nil {
+			t.Fatal(err)
+		}
+		pod := domain.PodInsight{Namespace: "media", App: "jellyfin", AnalyzedAt: at, Status: status}
+		if err := store.SavePodInsight(ctx, pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	page, err := store.ListInsights(ctx, domain.HistoryQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 1 || page.Items[0].Status != "warning" {
+		t.Errorf("insights = %+v, want the first one saved", page.Items)
+	}
+	pods, err := store.LatestPodInsights(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods) != 1 || pods[0].Status != "warning" {
+		t.Errorf("pod insights = %+v, want the first one saved", pods)
+	}
+}
@@ -0,0 +1,219 @@
+// Package httpclient builds the HTTP clients the outbound adapters use to reach
+// Prometheus, Loki, Overwatch and Alertmanager, applying per-backend
+// authentication and TLS settings.
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenRefresh is how long a bearer token read from file is reused before the
+// file is read again, so rotated tokens are picked up without a restart.
+const tokenRefresh = time.Minute
+
+// Options is a validated backend configuration. A nil *Options is valid and
+// adds nothing to requests.
+type Options struct {
+	headers   http.Header
+	basicUser string
+	basicPass string
+	token     string
+	tokenFile string
+	tls       *tls.Config
+	wrappers  []func(http.RoundTripper) http.RoundTripper
+
+	mu          sync.Mutex
+	cachedToken string
+	tokenRead   time.Time
+}
+
+// FromEnv reads the configuration for one backend from variables named after
+// prefix, e.g. with prefix "LOKI":
+//
+//	LOKI_BASIC_AUTH_USER, LOKI_BASIC_AUTH_PASSWORD   basic auth
+//	LOKI_BEARER_TOKEN, LOKI_BEARER_TOKEN_FILE        bearer token, literal or from file
+//	LOKI_HEADERS                                     extra headers, "Name=value,Name2=value2"
+//	LOKI_TENANT_ID                                   sent as X-Scope-OrgID
+//	LOKI_CA_FILE                                     PEM CA bundle for the server
+//	LOKI_CERT_FILE, LOKI_KEY_FILE                    client certificate for mutual TLS
+//	LOKI_TLS_INSECURE_SKIP_VERIFY                    "true" disables verification
+//
+// It returns nil when none of them are set.
+func FromEnv(prefix string) (*Options, error) {
+	env := func(name string) string { return strings.TrimSpace(os.Getenv(prefix + "_" + name)) }
+
+	o := &Options{
+		headers:   http.Header{},
+		basicUser: env("BASIC_AUTH_USER"),
+		basicPass: env("BASIC_AUTH_PASSWORD"),
+		token:     env("BEARER_TOKEN"),
+		tokenFile: env("BEARER_TOKEN_FILE"),
+	}
+	configured := o.basicUser != "" || o.token != "" || o.tokenFile != ""
+
+	if o.basicUser != "" && (o.token != "" || o.tokenFile != "") {
+		return nil, fmt.Errorf("%s: basic auth and bearer token are mutually exclusive", prefix)
+	}
+	if o.token != "" && o.tokenFile != "" {
+		return nil, fmt.Errorf("%s: set only one of %s_BEARER_TOKEN and %s_BEARER_TOKEN_FILE", prefix, prefix, prefix)
+	}
+	if o.tokenFile != "" {
+		if _, err := o.readToken(); err != nil {
+			return nil, fmt.Errorf("%s: %w", prefix, err)
+		}
+	}
+
+	if v := env("HEADERS"); v != "" {
+		configured = true
+		for _, pair := range strings.Split(v, ",") {
+			name, value, ok := strings.Cut(pair, "=")
+			name = strings.TrimSpace(name)
+			if !ok || name == "" {
+				return nil, fmt.Errorf("%s_HEADERS: %q is not Name=value", prefix, pair)
+			}
+			o.headers.Add(name, strings.TrimSpace(value))
+		}
+	}
+	if v := env("TENANT_ID"); v != "" {
+		configured = true
+		o.headers.Set("X-Scope-OrgID", v)
+	}
+
+	tlsConfig, err := loadTLS(prefix, env("CA_FILE"), env("CERT_FILE"), env("KEY_FILE"), env("TLS_INSECURE_SKIP_VERIFY") == "true")
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		configured = true
+		o.tls = tlsConfig
+	}
+
+	if !configured {
+		return nil, nil
+	}
+	return o, nil
+}
+
+func loadTLS(prefix, caFile, certFile, keyFile string, insecure bool) (*tls.Config, error) {
+	if caFile == "" && certFile == "" && keyFile == "" && !insecure {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: insecure} //nolint:gosec // opt-in
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s_CA_FILE: %w", prefix, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s_CA_FILE: no certificates found in %s", prefix, caFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, fmt.Errorf("%s: %s_CERT_FILE and %s_KEY_FILE must be set together", prefix, prefix, prefix)
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: load client certificate: %w", prefix, err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// Wrap registers a transport wrapper, such as retries, applied outside the
+// auth transport so every attempt carries fresh credentials. It may be called
+// on a nil *Options and returns the Options to use.
+func (o *Options) Wrap(wrapper func(http.RoundTripper) http.RoundTripper) *Options {
+	if o == nil {
+		o = &Options{headers: http.Header{}}
+	}
+	o.wrappers = append(o.wrappers, wrapper)
+	return o
+}
+
+// Transport applies the TLS settings to a clone of base and wraps it so that
+// every request carries the configured credentials and headers.
+func (o *Options) Transport(base *http.Transport) http.RoundTripper {
+	if o == nil {
+		return base
+	}
+	if o.tls != nil {
+		base = base.Clone()
+		base.TLSClientConfig = o.tls.Clone()
+	}
+	var rt http.RoundTripper = &authTransport{opts: o, next: base}
+	for _, wrap := range o.wrappers {
+		rt = wrap(rt)
+	}
+	return rt
+}
+
+// Client returns a client with the default transport, Options applied and the
+// given overall timeout.
+func (o *Options) Client(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: o.Transport(http.DefaultTransport.(*http.Transport).Clone()),
+		Timeout:   timeout,
+	}
+}
+
+func (o *Options) readToken() (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.cachedToken != "" && time.Since(o.tokenRead) < tokenRefresh {
+		return o.cachedToken, nil
+	}
+	raw, err := os.ReadFile(o.tokenFile)
+	if err != nil {
+		if o.cachedToken != "" {
+			// Keep using the last good token while the file is being rotated.
+			return o.cachedToken, nil
+		}
+		return "", fmt.Errorf("read bearer token: %w", err)
+	}
+	token := strings.TrimSpace(string(raw))
+	if token == "" {
+		return "", fmt.Errorf("bearer token file %s is empty", o.tokenFile)
+	}
+	o.cachedToken, o.tokenRead = token, time.Now()
+	return token, nil
+}
+
+type authTransport struct {
+	opts *Options
+	next http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	o := t.opts
+	req = req.Clone(req.Context())
+
+	for name, values := range o.headers {
+		req.Header[name] = append([]string(nil), values...)
+	}
+	switch {
+	case o.basicUser != "":
+		req.SetBasicAuth(o.basicUser, o.basicPass)
+	case o.token != "":
+		req.Header.Set("Authorization", "Bearer "+o.token)
+	case o.tokenFile != "":
+		token, err := o.readToken()
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,111 @@
+package kubernetes
+
+import (
+	"context"
+	"log"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+// probeEventWindow is how far back failed probe events are counted.
+const probeEventWindow = time.Hour
+
+// ListPodDiagnostics reports restarts, waiting and termination reasons,
+// scheduling failures and recent probe failures for every pod outside the
+// system namespaces. Probe failures come from events and are left out if
+// events can't be listed.
+func (r *kubernetesRepository) ListPodDiagnostics(ctx context.Context) ([]domain.PodDiagnostics, error) {
+	pods, err := r.client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	probes, err := r.probeFailures(ctx)
+	if err != nil {
+		log.Printf("[kubernetes] list probe events: %v", err)
+	}
+
+	result := make([]domain.PodDiagnostics, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		if systemNamespaces[pod.Namespace] || pod.Status.Phase == corev1.PodSucceeded {
+			continue
+		}
+
+		d := domain.PodDiagnostics{
+			Namespace:  pod.Namespace,
+			Pod:        pod.Name,
+			App:        podAppName(pod),
+			Node:       pod.Spec.NodeName,
+			Phase:      string(pod.Status.Phase),
+			Ready:      podHealth(pod) == "healthy",
+			Created:    pod.CreationTimestamp.Time,
+			Containers: make([]domain.ContainerDiagnostics, 0, len(pod.Status.ContainerStatuses)),
+		}
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse && cond.Reason == corev1.PodReasonUnschedulable {
+				d.Unschedulable = cond.Message
+			}
+		}
+		statuses := append(append([]corev1.ContainerStatus(nil), pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, cs := range statuses {
+			c := domain.ContainerDiagnostics{Name: cs.Name, Restarts: cs.RestartCount}
+			if w := cs.State.Waiting; w != nil {
+				c.Waiting, c.WaitingMessage = w.Reason, w.Message
+			}
+			// A container killed just now is still Terminated; otherwise the
+			// previous run says why it restarted.
+			if t := cs.State.Terminated; t != nil {
+				c.LastTerminated, c.LastExitCode = t.Reason, t.ExitCode
+			} else if t := cs.LastTerminationState.Terminated; t != nil {
+				c.LastTerminated, c.LastExitCode = t.Reason, t.ExitCode
+			}
+			d.Restarts += cs.RestartCount
+			d.Containers = append(d.Containers, c)
+		}
+		if p, ok := probes[pod.Namespace+"/"+pod.Name]; ok {
+			d.ProbeFailures, d.ProbeMessage = p.count, p.message
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+type probeFailure struct {
+	count   int32
+	message string
+}
+
+// probeFailures counts the Unhealthy events of each pod, keyed "ns/pod",
+// seen within probeEventWindow, keeping the latest message.
+func (r *kubernetesRepository) probeFailures(ctx context.Context) (map[string]probeFailure, error) {
+	events, err := r.client.CoreV1().Events("").List(ctx, metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=Pod,reason=Unhealthy",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	since := time.Now().Add(-probeEventWindow)
+	latest := make(map[string]time.Time)
+	result := make(map[string]probeFailure)
+	for _, ev := range events.Items {
+		seen := ev.LastTimestamp.Time
+		if seen.IsZero() {
+			seen = ev.EventTime.Time
+		}
+		if seen.Before(since) {
+			continue
+		}
+		key := ev.InvolvedObject.Namespace + "/" + ev.InvolvedObject.Name
+		p := result[key]
+		p.count += max(ev.Count, 1)
+		if seen.After(latest[key]) {
+			latest[key], p.message = seen, ev.Message
+		}
+		result[key] = p
+	}
+	return result, nil
+}
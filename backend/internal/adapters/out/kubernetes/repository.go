@@ -21,20 +21,17 @@ import (
 	portout "github.com/isaacwallace123/portfolio-infra/internal/core/ports/out"
 )
 
-var systemNamespaces = map[string]bool{
-	"kube-system":       true,
-	"kube-public":       true,
-	"kube-node-lease":   true,
-}
+var systemNamespaces = domain.SystemNamespaces
 
 type kubernetesRepository struct {
 	client        *kubernetes.Clientset
 	metricsClient *metricsv1beta1.Clientset
 	lokiURL       string
+	lokiClient    *http.Client
 }
 
-func NewKubernetesRepository(client *kubernetes.Clientset, metricsClient *metricsv1beta1.Clientset, lokiURL string) portout.ClusterRepository {
-	return &kubernetesRepository{client: client, metricsClient: metricsClient, lokiURL: lokiURL}
+func NewKubernetesRepository(client *kubernetes.Clientset, metricsClient *metricsv1beta1.Clientset, lokiURL string, lokiClient *http.Client) portout.ClusterRepository {
+	return &kubernetesRepository{client: client, metricsClient: metricsClient, lokiURL: lokiURL, lokiClient: lokiClient}
 }
 
 func (r *kubernetesRepository) Ping(ctx context.Context) error {
@@ -204,7 +201,7 @@ func (r *kubernetesRepository) getLokiLogs(ctx context.Context, namespace, pod s
 		return nil, err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := r.lokiClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -221,10 +218,17 @@ func (r *kubernetesRepository) getLokiLogs(ctx context.Context, namespace, pod s
 		return nil, err
 	}
 
+	// Prefix each line with its RFC3339 timestamp so Loki output matches the
+	// kubelet log format (PodLogOptions.Timestamps) used in the fallback path.
 	lines := make([]string, 0, limit)
 	for _, stream := range result.Data.Result {
 		for _, v := range stream.Values {
-			if len(v) >= 2 {
+			if len(v) < 2 {
+				continue
+			}
+			if ns, err := strconv.ParseInt(v[0], 10, 64); err == nil {
+				lines = append(lines, time.Unix(0, ns).UTC().Format(time.RFC3339Nano)+" "+v[1])
+			} else {
 				lines = append(lines, v[1])
 			}
 		}
@@ -347,6 +351,7 @@ func (r *kubernetesRepository) ListNodes(ctx context.Context) ([]domain.NodeInfo
 		}
 
 		status := "Unknown"
+		var pressure []string
 		for _, cond := range node.Status.Conditions {
 			if cond.Type == "Ready" {
 				if cond.Status == "True" {
@@ -354,6 +359,8 @@ func (r *kubernetesRepository) ListNodes(ctx context.Context) ([]domain.NodeInfo
 				} else {
 					status = "NotReady"
 				}
+			} else if cond.Status == corev1.ConditionTrue {
+				pressure = append(pressure, string(cond.Type))
 			}
 		}
 
@@ -369,11 +376,61 @@ func (r *kubernetesRepository) ListNodes(ctx context.Context) ([]domain.NodeInfo
 			MemoryGB:    memGB,
 			OSImage:     node.Status.NodeInfo.OSImage,
 			ProxmoxHost: node.Labels["proxmox-host"],
+			Pressure:    pressure,
 		})
 	}
 	return result, nil
 }
 
+// ListPodResources returns requests and limits for every scheduled, non-terminated
+// pod outside the system namespaces.
+func (r *kubernetesRepository) ListPodResources(ctx context.Context) ([]domain.PodResources, error) {
+	pods, err := r.client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.PodResources, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		if systemNamespaces[pod.Namespace] {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		res := domain.PodResources{
+			Namespace: pod.Namespace,
+			Pod:       pod.Name,
+			App:       podAppName(pod),
+			Node:      pod.Spec.NodeName,
+		}
+		cpuUnbounded, memUnbounded := false, false
+		for _, c := range pod.Spec.Containers {
+			res.CPURequest += c.Resources.Requests.Cpu().AsApproximateFloat64()
+			res.MemoryRequest += c.Resources.Requests.Memory().AsApproximateFloat64()
+			if lim, ok := c.Resources.Limits[corev1.ResourceCPU]; ok {
+				res.CPULimit += lim.AsApproximateFloat64()
+			} else {
+				cpuUnbounded = true
+			}
+			if lim, ok := c.Resources.Limits[corev1.ResourceMemory]; ok {
+				res.MemoryLimit += lim.AsApproximateFloat64()
+			} else {
+				memUnbounded = true
+			}
+		}
+		if cpuUnbounded {
+			res.CPULimit = 0
+		}
+		if memUnbounded {
+			res.MemoryLimit = 0
+		}
+		result = append(result, res)
+	}
+	return result, nil
+}
+
 func (r *kubernetesRepository) ListDependencies(ctx context.Context) ([]domain.AppDependency, error) {
 	services, err := r.client.CoreV1().Services("").List(ctx, metav1.ListOptions{})
 	if err != nil {
@@ -541,6 +598,14 @@ func parseID(id string) (namespace, podName string, err error) {
 func podStateAndStatus(pod corev1.Pod) (state, status string) {
 	state = strings.ToLower(string(pod.Status.Phase))
 	status = pod.Status.Message
+	// A pod stays Running while a container is stuck waiting, e.g. in
+	// CrashLoopBackOff; that reason says more than the phase.
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" && cs.State.Waiting.Reason != "ContainerCreating" {
+			status = cs.State.Waiting.Reason
+			break
+		}
+	}
 	if status == "" {
 		status = string(pod.Status.Phase)
 	}
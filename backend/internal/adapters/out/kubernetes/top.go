@@ -0,0 +1,136 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+// GetTopConsumers is the fallback ranking used when Prometheus is unavailable.
+// CPU and memory come from metrics-server, in the same units as the Prometheus
+// rankings (cores and bytes for pods, percent of capacity for nodes). Restarts
+// come from pod status and count since each container was created, not over
+// q.Window. Network and disk I/O are not available. Entries come back unsorted.
+func (r *kubernetesRepository) GetTopConsumers(ctx context.Context, q domain.TopQuery) (*domain.TopConsumers, error) {
+	result := &domain.TopConsumers{
+		By:            q.By,
+		Kind:          q.Kind,
+		WindowSeconds: int64(q.Window / time.Second),
+		Source:        domain.TopSourceMetricsServer,
+	}
+
+	var err error
+	switch {
+	case q.By == domain.TopByRestarts && (q.Kind == domain.TopKindPod || q.Kind == domain.TopKindNode):
+		result.Unit = "restarts"
+		result.Entries, err = r.topRestarts(ctx, q.Kind)
+		result.Warnings = []string{"restart counts are totals since container creation, not over the requested window"}
+	case (q.By == domain.TopByCPU || q.By == domain.TopByMemory) && q.Kind == domain.TopKindPod:
+		result.Unit = "cores"
+		if q.By == domain.TopByMemory {
+			result.Unit = "bytes"
+		}
+		result.Entries, err = r.topPodUsage(ctx, q.By)
+	case (q.By == domain.TopByCPU || q.By == domain.TopByMemory) && q.Kind == domain.TopKindNode:
+		result.Unit = "percent"
+		result.Entries, err = r.topNodeUsage(ctx, q.By)
+	default:
+		return nil, fmt.Errorf("ranking %ss by %s requires prometheus", q.Kind, q.By)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *kubernetesRepository) topPodUsage(ctx context.Context, by string) ([]domain.TopEntry, error) {
+	podMetrics, err := r.metricsClient.MetricsV1beta1().PodMetricses("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]domain.TopEntry, 0, len(podMetrics.Items))
+	for _, pm := range podMetrics.Items {
+		if systemNamespaces[pm.Namespace] {
+			continue
+		}
+		var value float64
+		for _, c := range pm.Containers {
+			if by == domain.TopByCPU {
+				value += float64(c.Usage.Cpu().MilliValue()) / 1000
+			} else {
+				value += float64(c.Usage.Memory().Value())
+			}
+		}
+		entries = append(entries, domain.TopEntry{Name: pm.Name, Namespace: pm.Namespace, Value: value})
+	}
+	return entries, nil
+}
+
+func (r *kubernetesRepository) topNodeUsage(ctx context.Context, by string) ([]domain.TopEntry, error) {
+	nodeMetrics, err := r.metricsClient.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := r.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	capacity := make(map[string]float64, len(nodes.Items))
+	for _, node := range nodes.Items {
+		if by == domain.TopByCPU {
+			capacity[node.Name] = float64(node.Status.Capacity.Cpu().MilliValue())
+		} else {
+			capacity[node.Name] = float64(node.Status.Capacity.Memory().Value())
+		}
+	}
+
+	entries := make([]domain.TopEntry, 0, len(nodeMetrics.Items))
+	for _, nm := range nodeMetrics.Items {
+		total := capacity[nm.Name]
+		if total <= 0 {
+			continue
+		}
+		used := float64(nm.Usage.Memory().Value())
+		if by == domain.TopByCPU {
+			used = float64(nm.Usage.Cpu().MilliValue())
+		}
+		entries = append(entries, domain.TopEntry{Name: nm.Name, Value: used / total * 100})
+	}
+	return entries, nil
+}
+
+func (r *kubernetesRepository) topRestarts(ctx context.Context, kind string) ([]domain.TopEntry, error) {
+	pods, err := r.client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	byNode := make(map[string]float64)
+	entries := make([]domain.TopEntry, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		if systemNamespaces[pod.Namespace] {
+			continue
+		}
+		var restarts float64
+		for _, cs := range pod.Status.ContainerStatuses {
+			restarts += float64(cs.RestartCount)
+		}
+		if kind == domain.TopKindNode {
+			if pod.Spec.NodeName != "" {
+				byNode[pod.Spec.NodeName] += restarts
+			}
+			continue
+		}
+		entries = append(entries, domain.TopEntry{Name: pod.Name, Namespace: pod.Namespace, Value: restarts})
+	}
+	for node, restarts := range byNode {
+		entries = append(entries, domain.TopEntry{Name: node, Value: restarts})
+	}
+	return entries, nil
+}
@@ -0,0 +1,148 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+	portout "github.com/isaacwallace123/portfolio-infra/internal/core/ports/out"
+)
+
+// errorFilter matches the log lines counted as errors. Case-insensitive so that
+// "ERROR", "Error" and "error" level prefixes are all picked up.
+const errorFilter = `|~ "(?i)error"`
+
+type lokiRepository struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewLokiRepository(baseURL string, client *http.Client) portout.LogMetricsRepository {
+	return &lokiRepository{baseURL: baseURL, client: client}
+}
+
+// GetLogMetricsRange returns log lines/sec and error lines/sec for a namespace,
+// an app, an app within a namespace, or the whole cluster when both are
+// empty, computed with Loki metric queries.
+func (r *lokiRepository) GetLogMetricsRange(ctx context.Context, rng domain.TimeRange, namespace, app string) (*domain.LogMetricsRange, error) {
+	if r.baseURL == "" {
+		return nil, fmt.Errorf("loki not configured")
+	}
+
+	selector := streamSelector(namespace, app)
+	rateWin := logqlDuration(rng.RateWindow())
+
+	linesQuery := fmt.Sprintf(`sum(rate(%s [%s]))`, selector, rateWin)
+	errorsQuery := fmt.Sprintf(`sum(rate(%s %s [%s]))`, selector, errorFilter, rateWin)
+
+	log.Printf("[loki] log metrics query namespace=%q app=%q start=%s end=%s step=%s", namespace, app, rng.Start.Format(time.RFC3339), rng.End.Format(time.RFC3339), rng.Step)
+
+	runQuery := func(q string) []domain.MetricPoint {
+		pts, err := r.queryRange(ctx, q, rng)
+		if err != nil {
+			log.Printf("[loki] range query error: %v | query: %s", err, q)
+			return []domain.MetricPoint{}
+		}
+		return pts
+	}
+
+	return &domain.LogMetricsRange{
+		Lines:  runQuery(linesQuery),
+		Errors: runQuery(errorsQuery),
+	}, nil
+}
+
+// streamSelector builds a LogQL stream selector. Loki rejects selectors without
+// at least one non-empty matcher, so the cluster-wide selector matches any
+// non-empty namespace.
+func streamSelector(namespace, app string) string {
+	matchers := make([]string, 0, 2)
+	if namespace != "" {
+		matchers = append(matchers, fmt.Sprintf("namespace=%q", namespace))
+	}
+	if app != "" {
+		matchers = append(matchers, fmt.Sprintf("app=%q", app))
+	}
+	if len(matchers) == 0 {
+		return `{namespace=~".+"}`
+	}
+	return "{" + strings.Join(matchers, ", ") + "}"
+}
+
+// queryRange executes a LogQL metric query and returns the first series as time-series points.
+func (r *lokiRepository) queryRange(ctx context.Context, query string, rng domain.TimeRange) ([]domain.MetricPoint, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatInt(rng.Start.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(rng.End.UnixNano(), 10))
+	params.Set("step", logqlDuration(rng.Step))
+
+	endpoint := fmt.Sprintf("%s/loki/api/v1/query_range?%s", r.baseURL, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("loki: unexpected status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Status string `json:"status"`
+		Data   struct {
+			ResultType string `json:"resultType"`
+			Result     []struct {
+				Values [][]interface{} `json:"values"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || result.Status != "success" {
+		return nil, fmt.Errorf("invalid loki range response")
+	}
+	if result.Data.ResultType != "matrix" {
+		return nil, fmt.Errorf("unexpected loki result type %q", result.Data.ResultType)
+	}
+	if len(result.Data.Result) == 0 {
+		return []domain.MetricPoint{}, nil
+	}
+
+	points := make([]domain.MetricPoint, 0, len(result.Data.Result[0].Values))
+	for _, pair := range result.Data.Result[0].Values {
+		if len(pair) < 2 {
+			continue
+		}
+		ts, ok := pair[0].(float64)
+		if !ok {
+			continue
+		}
+		valStr, ok := pair[1].(string)
+		if !ok {
+			continue
+		}
+		val, err := strconv.ParseFloat(valStr, 64)
+		if err != nil {
+			continue
+		}
+		points = append(points, domain.NewMetricPoint(time.Unix(int64(ts), 0), val))
+	}
+	return points, nil
+}
+
+// logqlDuration formats a duration as whole seconds for LogQL range selectors and steps.
+func logqlDuration(d time.Duration) string {
+	return strconv.FormatInt(int64(d/time.Second), 10) + "s"
+}
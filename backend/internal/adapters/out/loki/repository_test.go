@@ -0,0 +1,21 @@
+package loki
+
+import "testing"
+
+func TestStreamSelector(t *testing.T) {
+	tests := []struct {
+		namespace, app, want string
+	}{
+		{namespace: "media", want: `{namespace="media"}`},
+		{app: "jellyfin", want: `{app="jellyfin"}`},
+		{namespace: "media", app: "jellyfin", want: `{namespace="media", app="jellyfin"}`},
+		// Loki needs a non-empty matcher, so the whole cluster is every
+		// stream with a namespace.
+		{want: `{namespace=~".+"}`},
+	}
+	for _, tt := range tests {
+		if got := streamSelector(tt.namespace, tt.app); got != tt.want {
+			t.Errorf("streamSelector(%q, %q) = %s, want %s", tt.namespace, tt.app, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+package metricsserver
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	metricsv1beta1 "k8s.io/metrics/pkg/client/clientset/versioned"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+	portout "github.com/isaacwallace123/portfolio-infra/internal/core/ports/out"
+)
+
+const (
+	defaultInterval  = 30 * time.Second
+	defaultRetention = 24 * time.Hour
+	// maxBuckets bounds every series; longer retentions get coarser buckets.
+	maxBuckets = 1440
+)
+
+// unavailableWarning is attached to range results, which only ever carry CPU
+// and memory.
+const unavailableWarning = "metrics-server only provides CPU and memory; install Prometheus for disk and network series"
+
+// Config tunes the sampler. Zero values use defaults.
+type Config struct {
+	// Interval is how often metrics.k8s.io is polled.
+	Interval time.Duration
+	// Retention is how far back samples are kept.
+	Retention time.Duration
+}
+
+// metricsServerRepository serves metrics from an in-memory history it builds by
+// polling metrics.k8s.io. It is meant for clusters without Prometheus: only CPU
+// and memory are available and history starts when the agent does.
+type metricsServerRepository struct {
+	metrics    *metricsv1beta1.Clientset
+	kube       *kubernetes.Clientset
+	interval   time.Duration
+	retention  time.Duration
+	resolution time.Duration
+
+	mu     sync.RWMutex
+	series map[string]*ring
+	latest *snapshot
+}
+
+// snapshot is the result of the most recent poll.
+type snapshot struct {
+	at      time.Time
+	cluster domain.NodeMetricsSnapshot
+	nodes   []domain.NodeMetrics
+	pods    []domain.PodUsage
+}
+
+// NewMetricsServerRepository starts polling in the background and returns a
+// repository backed by the samples it collects.
+func NewMetricsServerRepository(metrics *metricsv1beta1.Clientset, kube *kubernetes.Clientset, cfg Config) portout.MetricsRepository {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = defaultRetention
+	}
+	r := &metricsServerRepository{
+		metrics:    metrics,
+		kube:       kube,
+		interval:   cfg.Interval,
+		retention:  cfg.Retention,
+		resolution: sampleResolution(cfg.Interval, cfg.Retention),
+		series:     make(map[string]*ring),
+	}
+	go r.run()
+	return r
+}
+
+// sampleResolution is the bucket width: retention spread over maxBuckets, but
+// no finer than the poll interval, in whole seconds and at least one, since
+// rings are sized by retention/resolution.
+func sampleResolution(interval, retention time.Duration) time.Duration {
+	resolution := max(retention/maxBuckets, interval)
+	return max(resolution.Round(time.Second), time.Second)
+}
+
+func (r *metricsServerRepository) run() {
+	log.Printf("[metrics-server] sampling every %s, keeping %s at %s resolution", r.interval, r.retention, r.resolution)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), r.interval)
+		if err := r.poll(ctx); err != nil {
+			log.Printf("[metrics-server] poll failed: %v", err)
+		}
+		cancel()
+		<-ticker.C
+	}
+}
+
+func (r *metricsServerRepository) poll(ctx context.Context) error {
+	nodes, err := r.kube.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list nodes: %w", err)
+	}
+	nodeMetrics, err := r.metrics.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list node metrics: %w", err)
+	}
+	podMetrics, err := r.metrics.MetricsV1beta1().PodMetricses("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list pod metrics: %w", err)
+	}
+
+	now := time.Now()
+	snap := &snapshot{at: now}
+
+	type capacity struct{ cpuMillis, memBytes float64 }
+	capacities := make(map[string]capacity, len(nodes.Items))
+	for _, node := range nodes.Items {
+		capacities[node.Name] = capacity{
+			cpuMillis: float64(node.Status.Capacity.Cpu().MilliValue()),
+			memBytes:  float64(node.Status.Capacity.Memory().Value()),
+		}
+	}
+
+	var cpuSum, memSum, memTotal float64
+	for _, nm := range nodeMetrics.Items {
+		c, ok := capacities[nm.Name]
+		if !ok || c.cpuMillis <= 0 || c.memBytes <= 0 {
+			continue
+		}
+		cpu := float64(nm.Usage.Cpu().MilliValue()) / c.cpuMillis * 100
+		mem := float64(nm.Usage.Memory().Value()) / c.memBytes * 100
+		total := c.memBytes
+		snap.nodes = append(snap.nodes, domain.NodeMetrics{
+			NodeInfo:            domain.NodeInfo{Name: nm.Name},
+			NodeMetricsSnapshot: domain.NodeMetricsSnapshot{CPU: &cpu, Memory: &mem, TotalMemory: &total},
+		})
+		cpuSum += cpu
+		memSum += mem
+		memTotal += total
+	}
+	sort.Slice(snap.nodes, func(i, j int) bool { return snap.nodes[i].Name < snap.nodes[j].Name })
+	if n := float64(len(snap.nodes)); n > 0 {
+		cpu, mem := cpuSum/n, memSum/n
+		snap.cluster = domain.NodeMetricsSnapshot{CPU: &cpu, Memory: &mem, TotalMemory: &memTotal}
+	}
+
+	for _, pm := range podMetrics.Items {
+		usage := domain.PodUsage{Namespace: pm.Namespace, Pod: pm.Name}
+		for _, c := range pm.Containers {
+			usage.CPU += float64(c.Usage.Cpu().MilliValue()) / 1000
+			usage.Memory += float64(c.Usage.Memory().Value())
+		}
+		snap.pods = append(snap.pods, usage)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if snap.cluster.CPU != nil {
+		r.record(hostKey("cpu"), now, *snap.cluster.CPU)
+		r.record(hostKey("memory"), now, *snap.cluster.Memory)
+	}
+	for _, n := range snap.nodes {
+		r.record(nodeKey(n.Name, "cpu"), now, *n.CPU)
+		r.record(nodeKey(n.Name, "memory"), now, *n.Memory)
+	}
+	for _, p := range snap.pods {
+		r.record(podKey(p.Namespace, p.Pod, "cpu"), now, p.CPU)
+		r.record(podKey(p.Namespace, p.Pod, "memory"), now, p.Memory)
+	}
+	r.latest = snap
+	r.evict(now)
+	return nil
+}
+
+// record must be called with mu held.
+func (r *metricsServerRepository) record(key string, t time.Time, v float64) {
+	s, ok := r.series[key]
+	if !ok {
+		s = newRing(int(r.retention/r.resolution)+1, r.resolution)
+		r.series[key] = s
+	}
+	s.add(t, v)
+}
+
+// evict drops series of nodes and pods that have not reported within the
+// retention window. It must be called with mu held.
+func (r *metricsServerRepository) evict(now time.Time) {
+	cutoff := now.Add(-r.retention).Unix()
+	for key, s := range r.series {
+		if last, ok := s.last(); !ok || last.start < cutoff {
+			delete(r.series, key)
+		}
+	}
+}
+
+func hostKey(metric string) string         { return "host/" + metric }
+func nodeKey(node, metric string) string   { return "node/" + node + "/" + metric }
+func podKey(ns, pod, metric string) string { return "pod/" + ns + "/" + pod + "/" + metric }
+
+// seriesPoints returns the resampled points of a series, or an empty slice.
+func (r *metricsServerRepository) seriesPoints(key string, rng domain.TimeRange, scale float64) []domain.MetricPoint {
+	s, ok := r.series[key]
+	if !ok {
+		return []domain.MetricPoint{}
+	}
+	points := s.points(rng)
+	for i := range points {
+		points[i].Value *= scale
+	}
+	return points
+}
+
+func (r *metricsServerRepository) GetNodeMetrics(ctx context.Context) (*domain.ClusterMetrics, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.latest == nil {
+		return nil, fmt.Errorf("metrics-server: no samples collected yet")
+	}
+	nodes := make([]domain.NodeMetrics, len(r.latest.nodes))
+	copy(nodes, r.latest.nodes)
+	return &domain.ClusterMetrics{NodeMetricsSnapshot: r.latest.cluster, Nodes: nodes}, nil
+}
+
+// GetMetricsRange serves host or pod CPU and memory from the sampled history.
+// Pod CPU is reported as percent of one core and memory in bytes, matching the
+// Prometheus adapter. A bare pod name matches the first pod with that name.
+func (r *metricsServerRepository) GetMetricsRange(ctx context.Context, rng domain.TimeRange, containerName string) (*domain.MetricsRange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cpuKey, memKey, cpuScale := hostKey("cpu"), hostKey("memory"), 1.0
+	if containerName != "" {
+		namespace, pod, ok := r.resolvePod(containerName)
+		if !ok {
+			return nil, fmt.Errorf("metrics-server: no samples for pod %q", containerName)
+		}
+		cpuKey, memKey, cpuScale = podKey(namespace, pod, "cpu"), podKey(namespace, pod, "memory"), 100
+	}
+
+	return cpuMemoryRange(r.seriesPoints(cpuKey, rng, cpuScale), r.seriesPoints(memKey, rng, 1)), nil
+}
+
+func (r *metricsServerRepository) GetNodeMetricsRange(ctx context.Context, node string, rng domain.TimeRange) (*domain.MetricsRange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.series[nodeKey(node, "cpu")]; !ok {
+		return nil, fmt.Errorf("metrics-server: no samples for node %q", node)
+	}
+	return cpuMemoryRange(r.seriesPoints(nodeKey(node, "cpu"), rng, 1), r.seriesPoints(nodeKey(node, "memory"), rng, 1)), nil
+}
+
+func cpuMemoryRange(cpu, memory []domain.MetricPoint) *domain.MetricsRange {
+	empty := func() []domain.MetricPoint { return []domain.MetricPoint{} }
+	return &domain.MetricsRange{
+		CPU:          cpu,
+		Memory:       memory,
+		Disk:         empty(),
+		NetworkRx:    empty(),
+		NetworkTx:    empty(),
+		DiskRead:     empty(),
+		DiskWrite:    empty(),
+		CPUThrottled: empty(),
+		OOMEvents:    empty(),
+		Warnings:     []string{unavailableWarning},
+	}
+}
+
+// resolvePod finds the sampled pod for a "namespace/pod-name" ID or bare pod
+// name. It must be called with mu held.
+func (r *metricsServerRepository) resolvePod(containerName string) (namespace, pod string, ok bool) {
+	if ns, p, found := strings.Cut(containerName, "/"); found {
+		_, ok = r.series[podKey(ns, p, "cpu")]
+		return ns, p, ok
+	}
+	for key := range r.series {
+		parts := strings.Split(key, "/")
+		if len(parts) == 4 && parts[0] == "pod" && parts[2] == containerName {
+			return parts[1], parts[2], true
+		}
+	}
+	return "", "", false
+}
+
+func (r *metricsServerRepository) GetPodUsage(ctx context.Context) (*domain.PodUsageReport, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.latest == nil {
+		return nil, fmt.Errorf("metrics-server: no samples collected yet")
+	}
+	pods := make([]domain.PodUsage, len(r.latest.pods))
+	copy(pods, r.latest.pods)
+	return &domain.PodUsageReport{Pods: pods}, nil
+}
+
+func (r *metricsServerRepository) GetPodUsageRange(ctx context.Context, rng domain.TimeRange) (*domain.PodUsageRange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := &domain.PodUsageRange{CPU: []domain.Series{}, Memory: []domain.Series{}}
+	for key, s := range r.series {
+		parts := strings.Split(key, "/")
+		if len(parts) != 4 || parts[0] != "pod" {
+			continue
+		}
+		series := domain.Series{
+			Labels: map[string]string{"namespace": parts[1], "pod": parts[2]},
+			Points: s.points(rng),
+		}
+		if parts[3] == "cpu" {
+			result.CPU = append(result.CPU, series)
+		} else {
+			result.Memory = append(result.Memory, series)
+		}
+	}
+	return result, nil
+}
+
+// GetTopConsumers is not served from samples; the service falls back to the
+// cluster's live metrics-server ranking.
+func (r *metricsServerRepository) GetTopConsumers(ctx context.Context, q domain.TopQuery) (*domain.TopConsumers, error) {
+	return nil, fmt.Errorf("top consumers require prometheus")
+}
+
+func (r *metricsServerRepository) GetMetricsBreakdown(ctx context.Context, node string, rng domain.TimeRange) (*domain.MetricsBreakdown, error) {
+	return nil, fmt.Errorf("metrics breakdown requires prometheus")
+}
+
+// GetCapacityRange only has node memory; filesystem and PVC usage need Prometheus.
+func (r *metricsServerRepository) GetCapacityRange(ctx context.Context, rng domain.TimeRange) (*domain.CapacityRange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := &domain.CapacityRange{
+		Filesystems: []domain.Series{},
+		PVCs:        []domain.Series{},
+		Memory:      []domain.Series{},
+		Warnings:    []string{"metrics-server has no filesystem or PVC usage; only node memory is forecast"},
+	}
+	for key, s := range r.series {
+		parts := strings.Split(key, "/")
+		if len(parts) != 3 || parts[0] != "node" || parts[2] != "memory" {
+			continue
+		}
+		result.Memory = append(result.Memory, domain.Series{
+			Labels: map[string]string{"node": parts[1]},
+			Points: s.points(rng),
+		})
+	}
+	return result, nil
+}
+
+func (r *metricsServerRepository) GetMetricsHealth(ctx context.Context) (*domain.MetricsHealth, error) {
+	return nil, fmt.Errorf("metrics health requires prometheus")
+}
+
+func (r *metricsServerRepository) ListMetricDefinitions(ctx context.Context) ([]domain.MetricDefinition, error) {
+	return []domain.MetricDefinition{}, nil
+}
+
+func (r *metricsServerRepository) GetCatalogMetricRange(ctx context.Context, scope, name, node, containerName string, rng domain.TimeRange) (*domain.CatalogMetricRange, error) {
+	return nil, fmt.Errorf("catalog metrics require prometheus")
+}
@@ -0,0 +1,100 @@
+package metricsserver
+
+import (
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+// bucket averages every sample that falls within one resolution interval.
+type bucket struct {
+	start int64 // unix seconds, aligned to the ring's width
+	sum   float64
+	count int
+}
+
+func (b bucket) value() float64 {
+	return b.sum / float64(b.count)
+}
+
+// ring is a fixed-size, chronological buffer of buckets. Once full, each new
+// bucket overwrites the oldest one.
+type ring struct {
+	buckets []bucket
+	head    int
+	size    int
+	width   int64
+}
+
+func newRing(capacity int, width time.Duration) *ring {
+	return &ring{buckets: make([]bucket, capacity), width: int64(width / time.Second)}
+}
+
+func (r *ring) add(t time.Time, v float64) {
+	start := t.Unix() / r.width * r.width
+	if last, ok := r.lastRef(); ok && last.start == start {
+		last.sum += v
+		last.count++
+		return
+	}
+
+	b := bucket{start: start, sum: v, count: 1}
+	if r.size < len(r.buckets) {
+		r.buckets[(r.head+r.size)%len(r.buckets)] = b
+		r.size++
+		return
+	}
+	r.buckets[r.head] = b
+	r.head = (r.head + 1) % len(r.buckets)
+}
+
+func (r *ring) lastRef() (*bucket, bool) {
+	if r.size == 0 {
+		return nil, false
+	}
+	return &r.buckets[(r.head+r.size-1)%len(r.buckets)], true
+}
+
+// last returns the most recent bucket.
+func (r *ring) last() (bucket, bool) {
+	b, ok := r.lastRef()
+	if !ok {
+		return bucket{}, false
+	}
+	return *b, true
+}
+
+// points resamples the buckets inside rng onto rng.Step, averaging the buckets
+// that share a step. Steps without data are omitted, as Prometheus does.
+func (r *ring) points(rng domain.TimeRange) []domain.MetricPoint {
+	start, end := rng.Start.Unix(), rng.End.Unix()
+	step := int64(rng.Step / time.Second)
+	if step < 1 {
+		step = 1
+	}
+
+	points := []domain.MetricPoint{}
+	var cur int64 = -1
+	var sum float64
+	var n int
+	flush := func() {
+		if n > 0 {
+			points = append(points, domain.NewMetricPoint(time.Unix(start+cur*step, 0), sum/float64(n)))
+		}
+	}
+	for i := 0; i < r.size; i++ {
+		b := r.buckets[(r.head+i)%len(r.buckets)]
+		if b.start < start || b.start > end {
+			continue
+		}
+		idx := (b.start - start) / step
+		if idx != cur {
+			flush()
+			cur, sum, n = idx, 0, 0
+		}
+		sum += b.value()
+		n++
+	}
+	flush()
+	return points
+}
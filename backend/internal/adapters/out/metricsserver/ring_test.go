@@ -0,0 +1,134 @@
+package metricsserver
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+func TestSampleResolution(t *testing.T) {
+	tests := []struct {
+		name      string
+		interval  time.Duration
+		retention time.Duration
+		want      time.Duration
+	}{
+		{name: "defaults", interval: 30 * time.Second, retention: 24 * time.Hour, want: time.Minute},
+		{name: "interval wins", interval: 30 * time.Second, retention: time.Hour, want: 30 * time.Second},
+		{name: "long retention coarsens", interval: 30 * time.Second, retention: 7 * 24 * time.Hour, want: 7 * time.Minute},
+		{name: "rounded to seconds", interval: 1500 * time.Millisecond, retention: time.Minute, want: 2 * time.Second},
+		{name: "sub-second interval", interval: 200 * time.Millisecond, retention: time.Minute, want: time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sampleResolution(tt.interval, tt.retention); got != tt.want {
+				t.Errorf("sampleResolution(%s, %s) = %s, want %s", tt.interval, tt.retention, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRingAdd(t *testing.T) {
+	base := time.Unix(1_700_000_000, 0) // a multiple of 10s
+	type sample struct {
+		secs  int // after base
+		value float64
+	}
+
+	tests := []struct {
+		name     string
+		capacity int
+		samples  []sample
+		want     []bucket
+	}{
+		{
+			name:     "samples in one interval are averaged",
+			capacity: 4,
+			samples:  []sample{{0, 1}, {3, 3}, {9, 5}},
+			want:     []bucket{{start: base.Unix(), sum: 9, count: 3}},
+		},
+		{
+			name:     "new interval starts a bucket",
+			capacity: 4,
+			samples:  []sample{{0, 1}, {10, 2}, {25, 3}},
+			want: []bucket{
+				{start: base.Unix(), sum: 1, count: 1},
+				{start: base.Unix() + 10, sum: 2, count: 1},
+				{start: base.Unix() + 20, sum: 3, count: 1},
+			},
+		},
+		{
+			name:     "full ring drops the oldest",
+			capacity: 2,
+			samples:  []sample{{0, 1}, {10, 2}, {20, 3}, {30, 4}},
+			want: []bucket{
+				{start: base.Unix() + 20, sum: 3, count: 1},
+				{start: base.Unix() + 30, sum: 4, count: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRing(tt.capacity, 10*time.Second)
+			for _, s := range tt.samples {
+				r.add(base.Add(time.Duration(s.secs)*time.Second), s.value)
+			}
+			got := make([]bucket, r.size)
+			for i := range got {
+				got[i] = r.buckets[(r.head+i)%len(r.buckets)]
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buckets = %+v, want %+v", got, tt.want)
+			}
+			if last, ok := r.last(); !ok || last != tt.want[len(tt.want)-1] {
+				t.Errorf("last = %+v, %v; want %+v", last, ok, tt.want[len(tt.want)-1])
+			}
+		})
+	}
+}
+
+func TestRingPoints(t *testing.T) {
+	base := time.Unix(1_700_000_000, 0)
+	r := newRing(10, 10*time.Second)
+	for i, v := range []float64{1, 3, 5, 7, 9, 11} {
+		r.add(base.Add(time.Duration(i*10)*time.Second), v)
+	}
+
+	tests := []struct {
+		name string
+		rng  domain.TimeRange
+		want []domain.MetricPoint
+	}{
+		{
+			name: "native step",
+			rng:  domain.TimeRange{Start: base.Add(20 * time.Second), End: base.Add(40 * time.Second), Step: 10 * time.Second},
+			want: []domain.MetricPoint{
+				domain.NewMetricPoint(base.Add(20*time.Second), 5),
+				domain.NewMetricPoint(base.Add(30*time.Second), 7),
+				domain.NewMetricPoint(base.Add(40*time.Second), 9),
+			},
+		},
+		{
+			name: "coarser step averages buckets",
+			rng:  domain.TimeRange{Start: base, End: base.Add(time.Minute), Step: 30 * time.Second},
+			want: []domain.MetricPoint{
+				domain.NewMetricPoint(base, 3),
+				domain.NewMetricPoint(base.Add(30*time.Second), 9),
+			},
+		},
+		{
+			name: "range without data",
+			rng:  domain.TimeRange{Start: base.Add(time.Hour), End: base.Add(2 * time.Hour), Step: time.Minute},
+			want: []domain.MetricPoint{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.points(tt.rng); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("points = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+	portout "github.com/isaacwallace123/portfolio-infra/internal/core/ports/out"
+)
+
+// fileConfig is the notification config file. ${VAR} references are
+// expanded from the environment so secrets can stay out of the file; any
+// other "$" is kept as written:
+//
+//	sinks:
+//	  - name: discord
+//	    type: discord            # webhook, discord, slack, ntfy, gotify or smtp
+//	    url: ${DISCORD_WEBHOOK_URL}
+//	  - name: mail
+//	    type: smtp
+//	    host: smtp.example.com
+//	    username: alerts@example.com
+//	    password: ${SMTP_PASSWORD}
+//	    from: alerts@example.com
+//	    to: [me@example.com]
+//	rules:
+//	  - name: urgent
+//	    kinds: [anomaly, node_not_ready, crash_loop, alert]
+//	    minSeverity: high
+//	    sinks: [discord, mail]
+//	    maxPerHour: 10
+//	    dedupWindow: 1h
+type fileConfig struct {
+	Sinks []sinkConfig `json:"sinks"`
+	Rules []ruleConfig `json:"rules"`
+}
+
+type sinkConfig struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	URL  string `json:"url"`
+	// Secret signs generic webhooks; Token authenticates ntfy and Gotify.
+	Secret   string   `json:"secret"`
+	Token    string   `json:"token"`
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+type ruleConfig struct {
+	Name        string            `json:"name"`
+	Kinds       []string          `json:"kinds"`
+	MinSeverity string            `json:"minSeverity"`
+	Match       map[string]string `json:"match"`
+	Sinks       []string          `json:"sinks"`
+	MaxPerHour  int               `json:"maxPerHour"`
+	DedupWindow string            `json:"dedupWindow"`
+}
+
+// defaultDedupWindow applies to rules that don't set dedupWindow.
+const defaultDedupWindow = time.Hour
+
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces ${VAR} with the variable's value. Unlike os.ExpandEnv it
+// leaves bare $VAR alone, so a "$" in a password or URL survives.
+func expandEnv(s string) string {
+	return envRef.ReplaceAllStringFunc(s, func(ref string) string {
+		return os.Getenv(ref[2 : len(ref)-1])
+	})
+}
+
+// LoadConfig reads the notification config at path and builds its sinks,
+// keyed by name, and rules. HTTP sinks share client.
+func LoadConfig(path string, client *http.Client) (map[string]portout.Notifier, []domain.NotificationRule, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var file fileConfig
+	if err := yaml.UnmarshalStrict([]byte(expandEnv(string(raw))), &file); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	sinks := make(map[string]portout.Notifier, len(file.Sinks))
+	for i, sc := range file.Sinks {
+		if sc.Name == "" {
+			return nil, nil, fmt.Errorf("sink %d: name is required", i)
+		}
+		if _, dup := sinks[sc.Name]; dup {
+			return nil, nil, fmt.Errorf("sink %q defined twice", sc.Name)
+		}
+		sink, err := buildSink(sc, client)
+		if err != nil {
+			return nil, nil, fmt.Errorf("sink %q: %w", sc.Name, err)
+		}
+		sinks[sc.Name] = sink
+	}
+
+	rules := make([]domain.NotificationRule, 0, len(file.Rules))
+	for i, rc := range file.Rules {
+		name := rc.Name
+		if name == "" {
+			name = fmt.Sprintf("rule-%d", i)
+		}
+		if len(rc.Sinks) == 0 {
+			return nil, nil, fmt.Errorf("rule %q: at least one sink is required", name)
+		}
+		for _, s := range rc.Sinks {
+			if _, ok := sinks[s]; !ok {
+				return nil, nil, fmt.Errorf("rule %q: unknown sink %q", name, s)
+			}
+		}
+		for _, k := range rc.Kinds {
+			switch k {
+			case domain.NotifyAnomaly, domain.NotifyNodeNotReady, domain.NotifyCrashLoop, domain.NotifyAlert:
+			default:
+				return nil, nil, fmt.Errorf("rule %q: unknown kind %q", name, k)
+			}
+		}
+		if rc.MinSeverity != "" && domain.SeverityRank(rc.MinSeverity) == 0 {
+			return nil, nil, fmt.Errorf("rule %q: minSeverity must be low, medium or high", name)
+		}
+		window := defaultDedupWindow
+		if rc.DedupWindow != "" {
+			window, err = time.ParseDuration(rc.DedupWindow)
+			if err != nil {
+				return nil, nil, fmt.Errorf("rule %q: invalid dedupWindow: %w", name, err)
+			}
+		}
+		rules = append(rules, domain.NotificationRule{
+			Name:        name,
+			Kinds:       rc.Kinds,
+			MinSeverity: rc.MinSeverity,
+			Match:       rc.Match,
+			Sinks:       rc.Sinks,
+			MaxPerHour:  rc.MaxPerHour,
+			DedupWindow: window,
+		})
+	}
+	return sinks, rules, nil
+}
+
+func buildSink(sc sinkConfig, client *http.Client) (portout.Notifier, error) {
+	needURL := func() error {
+		if sc.URL == "" {
+			return fmt.Errorf("url is required for type %s", sc.Type)
+		}
+		return nil
+	}
+
+	switch sc.Type {
+	case "webhook":
+		if err := needURL(); err != nil {
+			return nil, err
+		}
+		return NewWebhookNotifier(sc.URL, sc.Secret, client), nil
+	case ChatDiscord, ChatSlack:
+		if err := needURL(); err != nil {
+			return nil, err
+		}
+		return NewChatNotifier(sc.URL, sc.Type, client), nil
+	case "ntfy":
+		if err := needURL(); err != nil {
+			return nil, err
+		}
+		return NewNtfyNotifier(sc.URL, sc.Token, client), nil
+	case "gotify":
+		if err := needURL(); err != nil {
+			return nil, err
+		}
+		if sc.Token == "" {
+			return nil, fmt.Errorf("token is required for gotify")
+		}
+		return NewGotifyNotifier(sc.URL, sc.Token, client), nil
+	case "smtp":
+		if sc.Host == "" || sc.From == "" || len(sc.To) == 0 {
+			return nil, fmt.Errorf("host, from and to are required for smtp")
+		}
+		return NewSMTPNotifier(SMTPConfig{
+			Host:     sc.Host,
+			Port:     sc.Port,
+			Username: sc.Username,
+			Password: sc.Password,
+			From:     sc.From,
+			To:       sc.To,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown type %q", sc.Type)
+	}
+}
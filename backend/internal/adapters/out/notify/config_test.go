@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("NOTIFY_TEST_TOKEN", "s3cret")
+	tests := []struct {
+		in, want string
+	}{
+		{in: "token: ${NOTIFY_TEST_TOKEN}", want: "token: s3cret"},
+		{in: "password: pa$$word", want: "password: pa$$word"},
+		{in: "password: $NOTIFY_TEST_TOKEN", want: "password: $NOTIFY_TEST_TOKEN"},
+		{in: "url: https://h/?a=${NOTIFY_TEST_UNSET}&b=$", want: "url: https://h/?a=&b=$"},
+		{in: "odd: ${not valid}", want: "odd: ${not valid}"},
+	}
+	for _, tt := range tests {
+		if got := expandEnv(tt.in); got != tt.want {
+			t.Errorf("expandEnv(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("NOTIFY_TEST_URL", "https://discord.example/hook")
+
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			yaml: `
+sinks:
+  - name: discord
+    type: discord
+    url: ${NOTIFY_TEST_URL}
+  - name: mail
+    type: smtp
+    host: smtp.example.com
+    password: pa$word
+    from: a@example.com
+    to: [b@example.com]
+rules:
+  - kinds: [anomaly, alert]
+    minSeverity: high
+    sinks: [discord, mail]
+    dedupWindow: 30m
+`,
+		},
+		{name: "unknown field", yaml: "sinks:\n  - name: x\n    type: webhook\n    url: u\n    colour: red\n", wantErr: "colour"},
+		{name: "sink without name", yaml: "sinks:\n  - type: webhook\n    url: u\n", wantErr: "name is required"},
+		{name: "duplicate sink", yaml: "sinks:\n  - {name: a, type: webhook, url: u}\n  - {name: a, type: webhook, url: u}\n", wantErr: "defined twice"},
+		{name: "unknown sink type", yaml: "sinks:\n  - {name: a, type: pager}\n", wantErr: `unknown type "pager"`},
+		{name: "missing url", yaml: "sinks:\n  - {name: a, type: ntfy}\n", wantErr: "url is required"},
+		{name: "gotify without token", yaml: "sinks:\n  - {name: a, type: gotify, url: u}\n", wantErr: "token is required"},
+		{name: "incomplete smtp", yaml: "sinks:\n  - {name: a, type: smtp, host: h}\n", wantErr: "required for smtp"},
+		{name: "rule without sinks", yaml: "rules:\n  - {name: r}\n", wantErr: "at least one sink"},
+		{name: "rule with unknown sink", yaml: "rules:\n  - {name: r, sinks: [nope]}\n", wantErr: `unknown sink "nope"`},
+		{name: "unknown kind", yaml: "sinks:\n  - {name: a, type: webhook, url: u}\nrules:\n  - {sinks: [a], kinds: [deploy]}\n", wantErr: `unknown kind "deploy"`},
+		{name: "bad severity", yaml: "sinks:\n  - {name: a, type: webhook, url: u}\nrules:\n  - {sinks: [a], minSeverity: urgent}\n", wantErr: "minSeverity"},
+		{name: "bad window", yaml: "sinks:\n  - {name: a, type: webhook, url: u}\nrules:\n  - {sinks: [a], dedupWindow: soon}\n", wantErr: "dedupWindow"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "notify.yaml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			sinks, rules, err := LoadConfig(path, http.DefaultClient)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadConfig() error = %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sinks) != 2 || len(rules) != 1 {
+				t.Fatalf("got %d sinks and %d rules, want 2 and 1", len(sinks), len(rules))
+			}
+			if chat := sinks["discord"].(*chatNotifier); chat.url != "https://discord.example/hook" {
+				t.Errorf("discord url = %q, want it expanded from the environment", chat.url)
+			}
+			if mail := sinks["mail"].(*smtpNotifier); mail.cfg.Password != "pa$word" || mail.cfg.Port != 587 {
+				t.Errorf("smtp config = %+v, want the literal password and port 587", mail.cfg)
+			}
+			r := rules[0]
+			if r.Name != "rule-0" || r.DedupWindow != 30*time.Minute || r.MinSeverity != "high" {
+				t.Errorf("rule = %+v", r)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+// Package notify implements notification sinks: signed JSON webhooks,
+// Discord and Slack incoming webhooks, ntfy and Gotify push, and SMTP email.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+	portout "github.com/isaacwallace123/portfolio-infra/internal/core/ports/out"
+)
+
+// post sends body to url and treats any non-2xx answer as an error.
+func post(ctx context.Context, client *http.Client, url, contentType string, body []byte, header http.Header) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+type webhookNotifier struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// NewWebhookNotifier posts the notification as JSON. With a secret, the body
+// is signed: X-Signature-256 is "sha256=" plus the hex HMAC-SHA256 of
+// X-Timestamp, a ".", and the body.
+func NewWebhookNotifier(url, secret string, client *http.Client) portout.Notifier {
+	return &webhookNotifier{url: url, secret: []byte(secret), client: client}
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, note domain.Notification) error {
+	body, err := json.Marshal(note)
+	if err != nil {
+		return err
+	}
+	header := http.Header{}
+	if len(n.secret) > 0 {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, n.secret)
+		mac.Write([]byte(ts + "."))
+		mac.Write(body)
+		header.Set("X-Timestamp", ts)
+		header.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	if err := post(ctx, n.client, n.url, "application/json", body, header); err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	return nil
+}
+
+const (
+	ChatDiscord = "discord"
+	ChatSlack   = "slack"
+)
+
+type chatNotifier struct {
+	url    string
+	flavor string
+	client *http.Client
+}
+
+// NewChatNotifier posts to a Discord or Slack incoming webhook (flavor
+// ChatDiscord or ChatSlack). Mattermost and Rocket.Chat accept the Slack form.
+func NewChatNotifier(url, flavor string, client *http.Client) portout.Notifier {
+	return &chatNotifier{url: url, flavor: flavor, client: client}
+}
+
+func (n *chatNotifier) Notify(ctx context.Context, note domain.Notification) error {
+	text := fmt.Sprintf("%s **%s**\n%s", severityEmoji(note.Severity), note.Title, note.Message)
+
+	var payload any
+	if n.flavor == ChatSlack {
+		// Slack mrkdwn bolds with single asterisks.
+		payload = map[string]string{"text": strings.ReplaceAll(text, "**", "*")}
+	} else {
+		payload = map[string]any{
+			"content": text,
+			// Pings in anomaly text (e.g. "@everyone" in a log line) stay inert.
+			"allowed_mentions": map[string]any{"parse": []string{}},
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if err := post(ctx, n.client, n.url, "application/json", body, nil); err != nil {
+		return fmt.Errorf("%s: %w", n.flavor, err)
+	}
+	return nil
+}
+
+func severityEmoji(severity string) string {
+	switch severity {
+	case domain.SeverityHigh:
+		return "🔴"
+	case domain.SeverityMedium:
+		return "🟠"
+	}
+	return "🔵"
+}
+
+type ntfyNotifier struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewNtfyNotifier publishes to an ntfy topic URL such as
+// https://ntfy.sh/homelab, optionally with an access token.
+func NewNtfyNotifier(url, token string, client *http.Client) portout.Notifier {
+	return &ntfyNotifier{url: url, token: token, client: client}
+}
+
+func (n *ntfyNotifier) Notify(ctx context.Context, note domain.Notification) error {
+	header := http.Header{}
+	header.Set("Title", mime.QEncoding.Encode("utf-8", note.Title))
+	header.Set("Tags", note.Kind)
+	header.Set("Priority", map[string]string{
+		domain.SeverityHigh:   "high",
+		domain.SeverityMedium: "default",
+	}[note.Severity])
+	if header.Get("Priority") == "" {
+		header.Set("Priority", "low")
+	}
+	if n.token != "" {
+		header.Set("Authorization", "Bearer "+n.token)
+	}
+	if err := post(ctx, n.client, n.url, "text/plain; charset=utf-8", []byte(note.Message), header); err != nil {
+		return fmt.Errorf("ntfy: %w", err)
+	}
+	return nil
+}
+
+type gotifyNotifier struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewGotifyNotifier sends to a Gotify server's /message endpoint with an
+// application token.
+func NewGotifyNotifier(baseURL, token string, client *http.Client) portout.Notifier {
+	return &gotifyNotifier{url: strings.TrimRight(baseURL, "/") + "/message", token: token, client: client}
+}
+
+func (n *gotifyNotifier) Notify(ctx context.Context, note domain.Notification) error {
+	priority := 2
+	switch note.Severity {
+	case domain.SeverityHigh:
+		priority = 8
+	case domain.SeverityMedium:
+		priority = 5
+	}
+	body, err := json.Marshal(map[string]any{"title": note.Title, "message": note.Message, "priority": priority})
+	if err != nil {
+		return err
+	}
+	header := http.Header{}
+	header.Set("X-Gotify-Key", n.token)
+	if err := post(ctx, n.client, n.url, "application/json", body, header); err != nil {
+		return fmt.Errorf("gotify: %w", err)
+	}
+	return nil
+}
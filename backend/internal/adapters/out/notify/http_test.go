@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	note := domain.Notification{Kind: domain.NotifyAlert, Key: "alert/x", Severity: domain.SeverityHigh, Title: "Alert firing: X"}
+
+	tests := []struct {
+		name      string
+		secret    string
+		wantSign  bool
+		wantError bool
+		status    int
+	}{
+		{name: "signed", secret: "hunter2", wantSign: true, status: http.StatusOK},
+		{name: "unsigned", status: http.StatusNoContent},
+		{name: "rejected", secret: "hunter2", wantSign: true, status: http.StatusUnauthorized, wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				ts, sig := r.Header.Get("X-Timestamp"), r.Header.Get("X-Signature-256")
+
+				if !tt.wantSign {
+					if ts != "" || sig != "" {
+						t.Errorf("unsigned webhook sent X-Timestamp %q and signature %q", ts, sig)
+					}
+				} else {
+					secs, err := strconv.ParseInt(ts, 10, 64)
+					if err != nil || time.Since(time.Unix(secs, 0)).Abs() > time.Minute {
+						t.Errorf("X-Timestamp = %q, want the current Unix time", ts)
+					}
+					mac := hmac.New(sha256.New, []byte(tt.secret))
+					mac.Write([]byte(ts + "." + string(body)))
+					if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); sig != want {
+						t.Errorf("X-Signature-256 = %q, want %q", sig, want)
+					}
+				}
+
+				var got domain.Notification
+				if err := json.Unmarshal(body, &got); err != nil || got.Key != note.Key {
+					t.Errorf("body = %s, want the notification", body)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			err := NewWebhookNotifier(srv.URL, tt.secret, srv.Client()).Notify(context.Background(), note)
+			if (err != nil) != tt.wantError {
+				t.Errorf("Notify() = %v, want error: %v", err, tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+	portout "github.com/isaacwallace123/portfolio-infra/internal/core/ports/out"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+type smtpNotifier struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPNotifier emails notifications. STARTTLS is used whenever the server
+// offers it, and credentials are only sent over TLS.
+func NewSMTPNotifier(cfg SMTPConfig) portout.Notifier {
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	return &smtpNotifier{cfg: cfg}
+}
+
+func (n *smtpNotifier) Notify(ctx context.Context, note domain.Notification) error {
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", n.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: [%s] %s\r\n", note.Severity, mime.QEncoding.Encode("utf-8", headerSafe(note.Title)))
+	fmt.Fprintf(&msg, "Date: %s\r\n", note.Time.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(note.Message, "\n", "\r\n"))
+	msg.WriteString("\r\n")
+
+	// smtp.SendMail has no context; run it aside so a hung server can't
+	// outlive the caller's deadline.
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+	done := make(chan error, 1)
+	go func() { done <- smtp.SendMail(addr, auth, n.cfg.From, n.cfg.To, []byte(msg.String())) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("smtp: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("smtp: %w", ctx.Err())
+	}
+}
+
+// headerSafe keeps a value on one header line.
+func headerSafe(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
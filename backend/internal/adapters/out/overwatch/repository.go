@@ -5,20 +5,27 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
 	portout "github.com/isaacwallace123/portfolio-infra/internal/core/ports/out"
 )
 
+// defaultHistoryLimit is what the agent asked Overwatch for before history
+// queries took a limit.
+const defaultHistoryLimit = 48
+
 type overwatchRepository struct {
 	baseURL string
 	client  *http.Client
 }
 
-func NewOverwatchRepository(baseURL string) portout.OverwatchRepository {
+func NewOverwatchRepository(baseURL string, client *http.Client) portout.OverwatchRepository {
 	return &overwatchRepository{
 		baseURL: baseURL,
-		client:  &http.Client{},
+		client:  client,
 	}
 }
 
@@ -66,9 +73,27 @@ func (r *overwatchRepository) GetAllPodInsights(ctx context.Context) ([]domain.P
 	return insights, nil
 }
 
-func (r *overwatchRepository) GetHistory(ctx context.Context) ([]domain.OverwatchInsight, error) {
+func (r *overwatchRepository) GetHistory(ctx context.Context, q domain.HistoryQuery) ([]domain.OverwatchInsight, error) {
+	params := url.Values{}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	// One more than the page so the caller can tell whether another follows.
+	params.Set("limit", strconv.Itoa(limit+1))
+	if !q.From.IsZero() {
+		params.Set("from", q.From.UTC().Format(time.RFC3339Nano))
+	}
+	upper, err := q.Upper()
+	if err != nil {
+		return nil, err
+	}
+	if !upper.IsZero() {
+		params.Set("to", upper.UTC().Format(time.RFC3339Nano))
+	}
+
 	var history []domain.OverwatchInsight
-	if err := r.get(ctx, "/history?limit=48", &history); err != nil {
+	if err := r.get(ctx, "/history?"+params.Encode(), &history); err != nil {
 		return nil, err
 	}
 	return history, nil
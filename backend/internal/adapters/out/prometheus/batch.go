@@ -0,0 +1,124 @@
+package prometheus
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+// queryJob is one catalog entry queued in a batch.
+type queryJob struct {
+	name    string
+	query   string
+	series  []domain.Series
+	samples []sample
+	err     error
+}
+
+// points returns the first series of the job, for entries aggregated down to a
+// single series.
+func (j *queryJob) points() []domain.MetricPoint {
+	if len(j.series) == 0 {
+		return []domain.MetricPoint{}
+	}
+	return j.series[0].Points
+}
+
+func (j *queryJob) allSeries() []domain.Series {
+	if j.series == nil {
+		return []domain.Series{}
+	}
+	return j.series
+}
+
+// queryBatch renders catalog entries for one scope and runs them concurrently,
+// bounded by the repository-wide worker pool. Failed queries yield empty data
+// and are reported in errors rather than failing the whole batch.
+type queryBatch struct {
+	r       *prometheusRepository
+	ctx     context.Context
+	rng     domain.TimeRange
+	instant bool
+	scope   string
+	vars    catalogVars
+	jobs    []*queryJob
+
+	mu       sync.Mutex
+	warnings []string
+	errors   []domain.QueryError
+}
+
+func (r *prometheusRepository) newRangeBatch(ctx context.Context, rng domain.TimeRange, scope string, vars catalogVars) *queryBatch {
+	return &queryBatch{r: r, ctx: ctx, rng: rng, scope: scope, vars: vars}
+}
+
+func (r *prometheusRepository) newInstantBatch(ctx context.Context, scope string, vars catalogVars) *queryBatch {
+	return &queryBatch{r: r, ctx: ctx, instant: true, scope: scope, vars: vars}
+}
+
+// add queues a catalog entry. Entries that fail to render are recorded as
+// errors straight away and never sent to Prometheus.
+func (b *queryBatch) add(name string) *queryJob {
+	job := &queryJob{name: name}
+	job.query, job.err = b.r.catalog.render(b.scope, name, b.vars)
+	if job.err != nil {
+		b.fail(job)
+	}
+	b.jobs = append(b.jobs, job)
+	return job
+}
+
+// run executes every queued job and waits for all of them.
+func (b *queryBatch) run() {
+	var wg sync.WaitGroup
+	for _, job := range b.jobs {
+		if job.err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(job *queryJob) {
+			defer wg.Done()
+			b.exec(job)
+		}(job)
+	}
+	wg.Wait()
+}
+
+func (b *queryBatch) exec(job *queryJob) {
+	select {
+	case b.r.workers <- struct{}{}:
+		defer func() { <-b.r.workers }()
+	case <-b.ctx.Done():
+		job.err = b.ctx.Err()
+		b.fail(job)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.r.queryTimeout)
+	defer cancel()
+
+	var warns []string
+	if b.instant {
+		job.samples, warns, job.err = b.r.queryInstant(ctx, job.query)
+	} else {
+		job.series, warns, job.err = b.r.queryRange(ctx, job.query, b.rng)
+	}
+
+	b.mu.Lock()
+	b.warnings = mergeWarnings(b.warnings, warns)
+	b.mu.Unlock()
+
+	if job.err != nil {
+		b.fail(job)
+	}
+}
+
+func (b *queryBatch) fail(job *queryJob) {
+	log.Printf("[prometheus] %s/%s query error: %v | query: %s", b.scope, job.name, job.err, job.query)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.errors = append(b.errors, domain.QueryError{Metric: job.name, Error: job.err.Error()})
+}
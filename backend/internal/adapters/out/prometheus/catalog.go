@@ -0,0 +1,161 @@
+package prometheus
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+//go:embed catalog.yml
+var defaultCatalog []byte
+
+var (
+	placeholderPattern = regexp.MustCompile(`{{\s*(\w+)\s*}}`)
+	knownPlaceholders  = map[string]bool{
+		"node": true, "pod": true, "namespace": true, "podSelector": true, "rateWindow": true,
+	}
+)
+
+type catalogFile struct {
+	Metrics []domain.MetricDefinition `json:"metrics"`
+}
+
+// Catalog holds the PromQL templates the adapter runs, keyed by scope and name.
+type Catalog struct {
+	defs map[string]domain.MetricDefinition
+}
+
+// catalogVars are the values substituted into a template.
+type catalogVars struct {
+	node       string
+	pod        string
+	namespace  string
+	rateWindow string
+}
+
+// LoadCatalog parses the embedded default catalog and, if path is non-empty,
+// merges the entries from that YAML file over it.
+func LoadCatalog(path string) (*Catalog, error) {
+	c := &Catalog{defs: make(map[string]domain.MetricDefinition)}
+	if err := c.merge(defaultCatalog, "default catalog"); err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return c, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read metric catalog: %w", err)
+	}
+	if err := c.merge(raw, path); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Catalog) merge(raw []byte, source string) error {
+	var file catalogFile
+	if err := yaml.UnmarshalStrict(raw, &file); err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+
+	seen := make(map[string]bool, len(file.Metrics))
+	for i, def := range file.Metrics {
+		if err := validateDefinition(def); err != nil {
+			return fmt.Errorf("%s: metric #%d: %w", source, i+1, err)
+		}
+		key := catalogKey(def.Scope, def.Name)
+		if seen[key] {
+			return fmt.Errorf("%s: duplicate metric %q in scope %q", source, def.Name, def.Scope)
+		}
+		seen[key] = true
+		c.defs[key] = def
+	}
+	return nil
+}
+
+func validateDefinition(def domain.MetricDefinition) error {
+	if def.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch def.Scope {
+	case domain.MetricScopeHost, domain.MetricScopeNode, domain.MetricScopePod:
+	default:
+		return fmt.Errorf("%s: scope must be one of host, node, pod", def.Name)
+	}
+	if strings.TrimSpace(def.Query) == "" {
+		return fmt.Errorf("%s: query is required", def.Name)
+	}
+	for _, m := range placeholderPattern.FindAllStringSubmatch(def.Query, -1) {
+		if !knownPlaceholders[m[1]] {
+			return fmt.Errorf("%s: unknown placeholder {{%s}}", def.Name, m[1])
+		}
+	}
+	return nil
+}
+
+func catalogKey(scope, name string) string {
+	return scope + "/" + name
+}
+
+// definitions returns every catalog entry sorted by scope then name.
+func (c *Catalog) definitions() []domain.MetricDefinition {
+	defs := make([]domain.MetricDefinition, 0, len(c.defs))
+	for _, def := range c.defs {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		if defs[i].Scope != defs[j].Scope {
+			return defs[i].Scope < defs[j].Scope
+		}
+		return defs[i].Name < defs[j].Name
+	})
+	return defs
+}
+
+// lookup returns the definition for a scope and name.
+func (c *Catalog) lookup(scope, name string) (domain.MetricDefinition, bool) {
+	def, ok := c.defs[catalogKey(scope, name)]
+	return def, ok
+}
+
+// render returns the PromQL for a scope and name with placeholders substituted.
+func (c *Catalog) render(scope, name string, vars catalogVars) (string, error) {
+	def, ok := c.lookup(scope, name)
+	if !ok {
+		return "", fmt.Errorf("metric %q not in catalog for scope %q", name, scope)
+	}
+
+	podSelector := fmt.Sprintf("pod=%s", promString(vars.pod))
+	if vars.namespace != "" {
+		podSelector += fmt.Sprintf(",namespace=%s", promString(vars.namespace))
+	}
+	values := map[string]string{
+		"node":        promEscape(vars.node),
+		"pod":         promEscape(vars.pod),
+		"namespace":   promEscape(vars.namespace),
+		"podSelector": podSelector,
+		"rateWindow":  vars.rateWindow,
+	}
+
+	return placeholderPattern.ReplaceAllStringFunc(def.Query, func(m string) string {
+		return values[placeholderPattern.FindStringSubmatch(m)[1]]
+	}), nil
+}
+
+// promEscape escapes a value for use between double quotes in PromQL.
+func promEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func promString(s string) string {
+	return `"` + promEscape(s) + `"`
+}
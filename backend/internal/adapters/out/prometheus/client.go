@@ -0,0 +1,242 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+// apiResponse is the envelope shared by every Prometheus HTTP API endpoint.
+// See https://prometheus.io/docs/prometheus/latest/querying/api/#format-overview.
+type apiResponse struct {
+	Status    string          `json:"status"`
+	Data      json.RawMessage `json:"data"`
+	ErrorType string          `json:"errorType"`
+	Error     string          `json:"error"`
+	Warnings  []string        `json:"warnings"`
+}
+
+// APIError is returned when Prometheus answers with status "error" or a
+// non-2xx HTTP status.
+type APIError struct {
+	StatusCode int
+	Type       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("prometheus: HTTP %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("prometheus: %s (HTTP %d): %s", e.Type, e.StatusCode, e.Message)
+}
+
+type queryData struct {
+	ResultType string          `json:"resultType"`
+	Result     json.RawMessage `json:"result"`
+}
+
+type vectorSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  []interface{}     `json:"value"`
+}
+
+type matrixSeries struct {
+	Metric map[string]string `json:"metric"`
+	Values [][]interface{}   `json:"values"`
+}
+
+// sample is a single labelled value from an instant query.
+type sample struct {
+	labels map[string]string
+	value  float64
+}
+
+// get calls a Prometheus API path and decodes the data field into out.
+// Warnings are logged and returned alongside the data.
+func (r *prometheusRepository) get(ctx context.Context, path string, params url.Values, out interface{}) ([]string, error) {
+	endpoint := r.baseURL + path
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		// Drop the *url.Error wrapper: its message repeats the full query URL.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("prometheus: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("prometheus: read response: %w", err)
+	}
+
+	var envelope apiResponse
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		// Proxies and load balancers in front of Prometheus answer with HTML or
+		// plain text; surface the status rather than a JSON syntax error.
+		if resp.StatusCode/100 != 2 {
+			return nil, &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		}
+		return nil, fmt.Errorf("prometheus: decode response: %w", err)
+	}
+
+	if envelope.Status != "success" || resp.StatusCode/100 != 2 {
+		msg := envelope.Error
+		if msg == "" {
+			msg = http.StatusText(resp.StatusCode)
+		}
+		return envelope.Warnings, &APIError{StatusCode: resp.StatusCode, Type: envelope.ErrorType, Message: msg}
+	}
+
+	for _, w := range envelope.Warnings {
+		log.Printf("[prometheus] warning: %s | path: %s", w, path)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(envelope.Data, out); err != nil {
+			return envelope.Warnings, fmt.Errorf("prometheus: decode data: %w", err)
+		}
+	}
+	return envelope.Warnings, nil
+}
+
+// queryInstant executes an instant query and returns every sample in the resulting vector.
+func (r *prometheusRepository) queryInstant(ctx context.Context, query string) ([]sample, []string, error) {
+	params := url.Values{}
+	params.Set("query", query)
+
+	var data queryData
+	warnings, err := r.get(ctx, "/api/v1/query", params, &data)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	switch data.ResultType {
+	case "vector":
+		var vec []vectorSample
+		if err := json.Unmarshal(data.Result, &vec); err != nil {
+			return nil, warnings, fmt.Errorf("prometheus: decode vector: %w", err)
+		}
+		samples := make([]sample, 0, len(vec))
+		for _, v := range vec {
+			_, val, ok := parsePair(v.Value)
+			if !ok {
+				continue
+			}
+			samples = append(samples, sample{labels: v.Metric, value: val})
+		}
+		return samples, warnings, nil
+	case "scalar":
+		var pair []interface{}
+		if err := json.Unmarshal(data.Result, &pair); err != nil {
+			return nil, warnings, fmt.Errorf("prometheus: decode scalar: %w", err)
+		}
+		_, val, ok := parsePair(pair)
+		if !ok {
+			return nil, warnings, fmt.Errorf("prometheus: unexpected scalar value")
+		}
+		return []sample{{labels: map[string]string{}, value: val}}, warnings, nil
+	default:
+		return nil, warnings, fmt.Errorf("prometheus: unexpected result type %q for instant query", data.ResultType)
+	}
+}
+
+// queryRange executes a range query and returns every series in the resulting matrix.
+func (r *prometheusRepository) queryRange(ctx context.Context, query string, rng domain.TimeRange) ([]domain.Series, []string, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatInt(rng.Start.Unix(), 10))
+	params.Set("end", strconv.FormatInt(rng.End.Unix(), 10))
+	params.Set("step", promDuration(rng.Step))
+
+	var data queryData
+	warnings, err := r.get(ctx, "/api/v1/query_range", params, &data)
+	if err != nil {
+		return nil, warnings, err
+	}
+	if data.ResultType != "matrix" {
+		return nil, warnings, fmt.Errorf("prometheus: unexpected result type %q for range query", data.ResultType)
+	}
+
+	var matrix []matrixSeries
+	if err := json.Unmarshal(data.Result, &matrix); err != nil {
+		return nil, warnings, fmt.Errorf("prometheus: decode matrix: %w", err)
+	}
+
+	series := make([]domain.Series, 0, len(matrix))
+	for _, m := range matrix {
+		points := make([]domain.MetricPoint, 0, len(m.Values))
+		for _, pair := range m.Values {
+			ts, val, ok := parsePair(pair)
+			if !ok {
+				continue
+			}
+			points = append(points, domain.NewMetricPoint(ts, val))
+		}
+		labels := m.Metric
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		series = append(series, domain.Series{Labels: labels, Points: points})
+	}
+	return series, warnings, nil
+}
+
+// parsePair decodes a Prometheus [<unix seconds>, "<value>"] pair.
+func parsePair(pair []interface{}) (time.Time, float64, bool) {
+	if len(pair) < 2 {
+		return time.Time{}, 0, false
+	}
+	ts, ok := pair[0].(float64)
+	if !ok {
+		return time.Time{}, 0, false
+	}
+	valStr, ok := pair[1].(string)
+	if !ok {
+		return time.Time{}, 0, false
+	}
+	val, err := strconv.ParseFloat(valStr, 64)
+	// NaN and ±Inf (e.g. 0/0 ratios on idle devices) cannot be encoded as JSON.
+	if err != nil || math.IsNaN(val) || math.IsInf(val, 0) {
+		return time.Time{}, 0, false
+	}
+	return time.Unix(int64(ts), 0), val, true
+}
+
+// mergeWarnings appends warnings not already present in dst. Queries against the
+// same Prometheus tend to repeat the same warning.
+func mergeWarnings(dst, src []string) []string {
+	for _, w := range src {
+		dup := false
+		for _, existing := range dst {
+			if existing == w {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			dst = append(dst, w)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,181 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+// Payloads of the Prometheus status endpoints used by GetMetricsHealth.
+type targetsData struct {
+	ActiveTargets []struct {
+		Labels             map[string]string `json:"labels"`
+		ScrapePool         string            `json:"scrapePool"`
+		ScrapeURL          string            `json:"scrapeUrl"`
+		Health             string            `json:"health"`
+		LastError          string            `json:"lastError"`
+		LastScrape         time.Time         `json:"lastScrape"`
+		LastScrapeDuration float64           `json:"lastScrapeDuration"`
+	} `json:"activeTargets"`
+}
+
+type rulesData struct {
+	Groups []struct {
+		Name  string `json:"name"`
+		File  string `json:"file"`
+		Rules []struct {
+			Name           string    `json:"name"`
+			Type           string    `json:"type"`
+			Health         string    `json:"health"`
+			LastError      string    `json:"lastError"`
+			LastEvaluation time.Time `json:"lastEvaluation"`
+		} `json:"rules"`
+	} `json:"groups"`
+}
+
+type runtimeData struct {
+	StartTime           time.Time `json:"startTime"`
+	ReloadConfigSuccess bool      `json:"reloadConfigSuccess"`
+	LastConfigTime      time.Time `json:"lastConfigTime"`
+	StorageRetention    string    `json:"storageRetention"`
+	CorruptionCount     int64     `json:"corruptionCount"`
+	GoroutineCount      int       `json:"goroutineCount"`
+}
+
+type tsdbData struct {
+	HeadStats struct {
+		NumSeries  int64 `json:"numSeries"`
+		ChunkCount int64 `json:"chunkCount"`
+		MinTime    int64 `json:"minTime"`
+		MaxTime    int64 `json:"maxTime"`
+	} `json:"headStats"`
+}
+
+// GetMetricsHealth reads the targets, rules, runtime and TSDB status endpoints
+// concurrently. Prometheus counts as up when any of them answered; the ones
+// that failed are listed in Errors.
+func (r *prometheusRepository) GetMetricsHealth(ctx context.Context) (*domain.MetricsHealth, error) {
+	if r.baseURL == "" {
+		return nil, fmt.Errorf("prometheus not configured")
+	}
+
+	var (
+		targets targetsData
+		rules   rulesData
+		runtime runtimeData
+		tsdb    tsdbData
+	)
+	calls := []struct {
+		name string
+		path string
+		out  interface{}
+		err  error
+	}{
+		{name: "targets", path: "/api/v1/targets", out: &targets},
+		{name: "rules", path: "/api/v1/rules", out: &rules},
+		{name: "runtimeinfo", path: "/api/v1/status/runtimeinfo", out: &runtime},
+		{name: "tsdb", path: "/api/v1/status/tsdb", out: &tsdb},
+	}
+
+	var wg sync.WaitGroup
+	for i := range calls {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			qctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+			defer cancel()
+			_, calls[i].err = r.get(qctx, calls[i].path, nil, calls[i].out)
+		}(i)
+	}
+	wg.Wait()
+
+	health := &domain.MetricsHealth{
+		Targets: domain.TargetsHealth{Unhealthy: []domain.TargetHealth{}},
+		Rules:   domain.RulesHealth{Failing: []domain.RuleHealth{}},
+	}
+	ok := make(map[string]bool, len(calls))
+	for _, c := range calls {
+		if c.err != nil {
+			health.Errors = append(health.Errors, domain.QueryError{Metric: c.name, Error: c.err.Error()})
+			continue
+		}
+		ok[c.name] = true
+		health.Up = true
+	}
+
+	if ok["targets"] {
+		for _, t := range targets.ActiveTargets {
+			health.Targets.Total++
+			switch t.Health {
+			case "up":
+				health.Targets.Up++
+				continue
+			case "down":
+				health.Targets.Down++
+			default:
+				health.Targets.Unknown++
+			}
+			health.Targets.Unhealthy = append(health.Targets.Unhealthy, domain.TargetHealth{
+				Job:                t.Labels["job"],
+				Instance:           t.Labels["instance"],
+				ScrapePool:         t.ScrapePool,
+				ScrapeURL:          t.ScrapeURL,
+				Health:             t.Health,
+				LastError:          t.LastError,
+				LastScrape:         t.LastScrape,
+				LastScrapeDuration: t.LastScrapeDuration,
+			})
+		}
+	}
+
+	if ok["rules"] {
+		health.Rules.Groups = len(rules.Groups)
+		for _, g := range rules.Groups {
+			groupFailing := false
+			for _, rule := range g.Rules {
+				health.Rules.Rules++
+				if rule.Health != "err" {
+					continue
+				}
+				groupFailing = true
+				health.Rules.Failing = append(health.Rules.Failing, domain.RuleHealth{
+					Group:          g.Name,
+					File:           g.File,
+					Name:           rule.Name,
+					Type:           rule.Type,
+					Health:         rule.Health,
+					LastError:      rule.LastError,
+					LastEvaluation: rule.LastEvaluation,
+				})
+			}
+			if groupFailing {
+				health.Rules.FailingGroups++
+			}
+		}
+	}
+
+	if ok["runtimeinfo"] {
+		health.Runtime = &domain.PrometheusRuntime{
+			StartTime:           runtime.StartTime,
+			ReloadConfigSuccess: runtime.ReloadConfigSuccess,
+			LastConfigTime:      runtime.LastConfigTime,
+			StorageRetention:    runtime.StorageRetention,
+			CorruptionCount:     runtime.CorruptionCount,
+			GoroutineCount:      runtime.GoroutineCount,
+		}
+	}
+
+	if ok["tsdb"] {
+		health.TSDB = &domain.TSDBStats{
+			NumSeries:  tsdb.HeadStats.NumSeries,
+			ChunkCount: tsdb.HeadStats.ChunkCount,
+			MinTime:    tsdb.HeadStats.MinTime,
+			MaxTime:    tsdb.HeadStats.MaxTime,
+		}
+	}
+
+	return health, nil
+}
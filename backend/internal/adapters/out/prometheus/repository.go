@@ -2,371 +2,457 @@ package prometheus
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
-	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/isaacwallace123/portfolio-infra/internal/adapters/out/httpclient"
 	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
 	portout "github.com/isaacwallace123/portfolio-infra/internal/core/ports/out"
 )
 
-type prometheusRepository struct {
-	baseURL string
+// instantRateWindow is the rate() lookback used for instant queries.
+const instantRateWindow = "5m"
+
+// snapshotFields maps each NodeMetricsSnapshot field to its host-scope catalog
+// entry. The per-node variant of every entry is the same name suffixed "ByNode".
+var snapshotFields = []struct {
+	name  string
+	field func(*domain.NodeMetricsSnapshot) **float64
+}{
+	{"cpu", func(s *domain.NodeMetricsSnapshot) **float64 { return &s.CPU }},
+	{"memory", func(s *domain.NodeMetricsSnapshot) **float64 { return &s.Memory }},
+	{"disk", func(s *domain.NodeMetricsSnapshot) **float64 { return &s.Disk }},
+	{"load1", func(s *domain.NodeMetricsSnapshot) **float64 { return &s.Load1 }},
+	{"uptime", func(s *domain.NodeMetricsSnapshot) **float64 { return &s.Uptime }},
+	{"totalMemory", func(s *domain.NodeMetricsSnapshot) **float64 { return &s.TotalMemory }},
+	{"diskRead", func(s *domain.NodeMetricsSnapshot) **float64 { return &s.DiskReadRate }},
+	{"diskWrite", func(s *domain.NodeMetricsSnapshot) **float64 { return &s.DiskWriteRate }},
+	{"networkRx", func(s *domain.NodeMetricsSnapshot) **float64 { return &s.NetworkRxRate }},
+	{"networkTx", func(s *domain.NodeMetricsSnapshot) **float64 { return &s.NetworkTxRate }},
 }
 
-func NewPrometheusRepository(baseURL string) portout.MetricsRepository {
-	return &prometheusRepository{baseURL: baseURL}
+const (
+	defaultMaxConcurrency = 8
+	defaultQueryTimeout   = 10 * time.Second
+)
+
+// Config tunes how the adapter talks to Prometheus. Zero values use defaults.
+type Config struct {
+	// MaxConcurrency bounds in-flight queries across all requests.
+	MaxConcurrency int
+	// QueryTimeout bounds each individual query.
+	QueryTimeout time.Duration
+	// HTTP carries authentication and TLS settings; nil sends plain requests.
+	HTTP *httpclient.Options
 }
 
-func (r *prometheusRepository) GetNodeMetrics(ctx context.Context) (map[string]interface{}, error) {
-	if r.baseURL == "" {
-		return nil, fmt.Errorf("prometheus not configured")
-	}
+type prometheusRepository struct {
+	baseURL      string
+	catalog      *Catalog
+	client       *http.Client
+	workers      chan struct{}
+	queryTimeout time.Duration
+}
 
-	queries := map[string]string{
-		"cpu":           `100 - (avg(rate(node_cpu_seconds_total{mode="idle"}[5m])) * 100)`,
-		"memory":        `(1 - node_memory_MemAvailable_bytes / node_memory_MemTotal_bytes) * 100`,
-		"disk":          `(1 - node_filesystem_avail_bytes{mountpoint=~"/|/mnt/user|/mnt/cache",fstype!="tmpfs"} / node_filesystem_size_bytes{mountpoint=~"/|/mnt/user|/mnt/cache",fstype!="tmpfs"}) * 100`,
-		"uptime":        `node_time_seconds - node_boot_time_seconds`,
-		"totalMemory":   `node_memory_MemTotal_bytes`,
-		"diskReadRate":  `sum(rate(node_disk_read_bytes_total{device!~"loop.*|sr.*"}[5m]))`,
-		"diskWriteRate": `sum(rate(node_disk_written_bytes_total{device!~"loop.*|sr.*"}[5m]))`,
-		"networkRxRate": `sum(rate(node_network_receive_bytes_total{device!~"lo|docker.*|br-.*|veth.*"}[5m]))`,
-		"networkTxRate": `sum(rate(node_network_transmit_bytes_total{device!~"lo|docker.*|br-.*|veth.*"}[5m]))`,
-	}
-
-	results := make(map[string]interface{})
-	for name, query := range queries {
-		val, err := r.queryInstant(ctx, query)
-		if err != nil {
-			results[name] = nil
-			continue
-		}
-		results[name] = val
+func NewPrometheusRepository(baseURL string, catalog *Catalog, cfg Config) portout.MetricsRepository {
+	if cfg.MaxConcurrency <= 0 {
+		cfg.MaxConcurrency = defaultMaxConcurrency
+	}
+	if cfg.QueryTimeout <= 0 {
+		cfg.QueryTimeout = defaultQueryTimeout
+	}
+	return &prometheusRepository{
+		baseURL:      baseURL,
+		catalog:      catalog,
+		client:       newHTTPClient(cfg.MaxConcurrency, cfg.HTTP),
+		workers:      make(chan struct{}, cfg.MaxConcurrency),
+		queryTimeout: cfg.QueryTimeout,
 	}
+}
 
-	return results, nil
+// newHTTPClient returns a client whose idle pool can hold a connection for every
+// worker, so concurrent queries reuse connections instead of re-dialing.
+func newHTTPClient(maxConcurrency int, opts *httpclient.Options) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = maxConcurrency * 2
+	transport.MaxIdleConnsPerHost = maxConcurrency
+	transport.MaxConnsPerHost = maxConcurrency
+	transport.IdleConnTimeout = 90 * time.Second
+	transport.ResponseHeaderTimeout = 30 * time.Second
+	transport.DialContext = (&net.Dialer{
+		Timeout:   5 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+
+	return &http.Client{
+		Transport: opts.Transport(transport),
+		// Per-query deadlines come from the context; this is a backstop.
+		Timeout: 60 * time.Second,
+	}
 }
 
-// GetMetricsRange returns CPU and memory time-series for either a specific container
-// (using cAdvisor metrics) or the host (using node_exporter metrics).
-// containerName matches the Docker container name, e.g. "portfolio_frontend".
-// duration must be one of: "5m", "15m", "1h", "24h".
-func (r *prometheusRepository) GetMetricsRange(ctx context.Context, duration, containerName string) (*domain.MetricsRange, error) {
+// GetNodeMetrics returns the cluster-wide snapshot and a snapshot for every node
+// reporting node_exporter metrics, sorted by node name. Only NodeInfo.Name is
+// set on each node; the caller joins in the rest of the inventory.
+func (r *prometheusRepository) GetNodeMetrics(ctx context.Context) (*domain.ClusterMetrics, error) {
 	if r.baseURL == "" {
 		return nil, fmt.Errorf("prometheus not configured")
 	}
 
-	type rangeCfg struct {
-		window  time.Duration
-		step    string
-		rateWin string
+	b := r.newInstantBatch(ctx, domain.MetricScopeHost, catalogVars{rateWindow: instantRateWindow})
+	clusterJobs := make([]*queryJob, len(snapshotFields))
+	nodeJobs := make([]*queryJob, len(snapshotFields))
+	for i, f := range snapshotFields {
+		clusterJobs[i] = b.add(f.name)
+		nodeJobs[i] = b.add(f.name + "ByNode")
 	}
-
-	cfgs := map[string]rangeCfg{
-		"5m":  {5 * time.Minute, "10s", "1m"},
-		"15m": {15 * time.Minute, "15s", "1m"},
-		"1h":  {60 * time.Minute, "60s", "5m"},
-		"24h": {24 * time.Hour, "1440s", "15m"},
+	b.run()
+
+	result := &domain.ClusterMetrics{Nodes: []domain.NodeMetrics{}, Errors: b.errors}
+	byNode := make(map[string]*domain.NodeMetricsSnapshot)
+	for i, f := range snapshotFields {
+		if samples := clusterJobs[i].samples; len(samples) > 0 {
+			v := samples[0].value
+			*f.field(&result.NodeMetricsSnapshot) = &v
+		}
+		for _, s := range nodeJobs[i].samples {
+			node := s.labels["node"]
+			if node == "" {
+				continue
+			}
+			snap, ok := byNode[node]
+			if !ok {
+				snap = &domain.NodeMetricsSnapshot{}
+				byNode[node] = snap
+			}
+			v := s.value
+			*f.field(snap) = &v
+		}
 	}
 
-	cfg, ok := cfgs[duration]
-	if !ok {
-		cfg = cfgs["5m"]
+	names := make([]string, 0, len(byNode))
+	for node := range byNode {
+		names = append(names, node)
 	}
+	sort.Strings(names)
+	for _, node := range names {
+		result.Nodes = append(result.Nodes, domain.NodeMetrics{
+			NodeInfo:            domain.NodeInfo{Name: node},
+			NodeMetricsSnapshot: *byNode[node],
+		})
+	}
+
+	return result, nil
+}
 
-	now := time.Now()
-	start := now.Add(-cfg.window)
+// GetMetricsRange returns time-series for either a specific container (using cAdvisor
+// metrics) or the host (using node_exporter metrics).
+// containerName is the "namespace/pod-name" ID from the Kubernetes adapter.
+// Disk usage % is host-only; CPU throttling and OOM events are container-only.
+func (r *prometheusRepository) GetMetricsRange(ctx context.Context, rng domain.TimeRange, containerName string) (*domain.MetricsRange, error) {
+	if r.baseURL == "" {
+		return nil, fmt.Errorf("prometheus not configured")
+	}
 
-	var cpuQuery, memQuery string
+	log.Printf("[prometheus] range query container=%q start=%s end=%s step=%s promURL=%s", containerName, rng.Start.Format(time.RFC3339), rng.End.Format(time.RFC3339), rng.Step, r.baseURL)
 
+	vars := catalogVars{rateWindow: promDuration(rng.RateWindow())}
+	scope := domain.MetricScopeHost
 	if containerName != "" {
-		// containerName is "namespace/pod-name" from the Kubernetes adapter.
-		// Use k8s cAdvisor labels scraped by kubelet. container!="POD" filters
-		// out the pause/sandbox container.
-		parts := strings.SplitN(containerName, "/", 2)
-		var namespace, pod string
-		if len(parts) == 2 {
-			namespace, pod = parts[0], parts[1]
-		} else {
-			pod = containerName
-		}
+		scope = domain.MetricScopePod
+		vars.namespace, vars.pod = splitContainerName(containerName)
+	}
 
-		if namespace != "" {
-			cpuQuery = fmt.Sprintf(
-				`sum(rate(container_cpu_usage_seconds_total{pod=%q,namespace=%q,container!~"POD|"}[%s])) * 100`,
-				pod, namespace, cfg.rateWin,
-			)
-			memQuery = fmt.Sprintf(
-				`sum(container_memory_working_set_bytes{pod=%q,namespace=%q,container!~"POD|"})`,
-				pod, namespace,
-			)
-		} else {
-			cpuQuery = fmt.Sprintf(
-				`sum(rate(container_cpu_usage_seconds_total{pod=%q,container!~"POD|"}[%s])) * 100`,
-				pod, cfg.rateWin,
-			)
-			memQuery = fmt.Sprintf(
-				`sum(container_memory_working_set_bytes{pod=%q,container!~"POD|"})`,
-				pod,
-			)
-		}
+	b := r.newRangeBatch(ctx, rng, scope, vars)
+	cpu, mem := b.add("cpu"), b.add("memory")
+	netRx, netTx := b.add("networkRx"), b.add("networkTx")
+	diskRead, diskWrite := b.add("diskRead"), b.add("diskWrite")
+	empty := &queryJob{}
+	disk, throttled, oom := empty, empty, empty
+	if containerName == "" {
+		disk = b.add("disk")
 	} else {
-		// Host-level metrics via node_exporter.
-		cpuQuery = fmt.Sprintf(`100 - (avg(rate(node_cpu_seconds_total{mode="idle"}[%s])) * 100)`, cfg.rateWin)
-		memQuery = `(1 - node_memory_MemAvailable_bytes / node_memory_MemTotal_bytes) * 100`
+		throttled, oom = b.add("cpuThrottled"), b.add("oomEvents")
 	}
+	b.run()
 
-	log.Printf("[prometheus] range query container=%q duration=%s promURL=%s", containerName, duration, r.baseURL)
+	return &domain.MetricsRange{
+		CPU:          cpu.points(),
+		Memory:       mem.points(),
+		Disk:         disk.points(),
+		NetworkRx:    netRx.points(),
+		NetworkTx:    netTx.points(),
+		DiskRead:     diskRead.points(),
+		DiskWrite:    diskWrite.points(),
+		CPUThrottled: throttled.points(),
+		OOMEvents:    oom.points(),
+		Warnings:     b.warnings,
+		Errors:       b.errors,
+	}, nil
+}
 
-	cpuPoints, err := r.queryRange(ctx, cpuQuery, start, now, cfg.step)
-	if err != nil {
-		log.Printf("[prometheus] cpu query error: %v | query: %s", err, cpuQuery)
-		cpuPoints = []domain.MetricPoint{}
-	} else {
-		log.Printf("[prometheus] cpu query returned %d points", len(cpuPoints))
+// GetNodeMetricsRange returns CPU, memory, network, and disk time-series for a specific k8s node.
+// The node parameter is the Kubernetes node name, which matches the `node` label added by
+// kube-prometheus-stack's node_exporter relabeling.
+func (r *prometheusRepository) GetNodeMetricsRange(ctx context.Context, node string, rng domain.TimeRange) (*domain.MetricsRange, error) {
+	if r.baseURL == "" {
+		return nil, fmt.Errorf("prometheus not configured")
 	}
 
-	memPoints, err := r.queryRange(ctx, memQuery, start, now, cfg.step)
-	if err != nil {
-		log.Printf("[prometheus] mem query error: %v | query: %s", err, memQuery)
-		memPoints = []domain.MetricPoint{}
-	} else {
-		log.Printf("[prometheus] mem query returned %d points", len(memPoints))
-	}
+	log.Printf("[prometheus] node range query node=%q start=%s end=%s step=%s", node, rng.Start.Format(time.RFC3339), rng.End.Format(time.RFC3339), rng.Step)
 
-	// Disk % and I/O rates are host-level only; return empty for container-specific queries.
-	var diskPoints, networkRxPoints, networkTxPoints, diskReadPoints, diskWritePoints []domain.MetricPoint
-	if containerName == "" {
-		diskQuery := `(1 - node_filesystem_avail_bytes{mountpoint=~"/|/mnt/user|/mnt/cache",fstype!="tmpfs"} / node_filesystem_size_bytes{mountpoint=~"/|/mnt/user|/mnt/cache",fstype!="tmpfs"}) * 100`
-		diskPoints, err = r.queryRange(ctx, diskQuery, start, now, cfg.step)
-		if err != nil {
-			diskPoints = []domain.MetricPoint{}
-		}
+	vars := catalogVars{node: node, rateWindow: promDuration(rng.RateWindow())}
+	b := r.newRangeBatch(ctx, rng, domain.MetricScopeNode, vars)
+	cpu, mem, disk := b.add("cpu"), b.add("memory"), b.add("disk")
+	netRx, netTx := b.add("networkRx"), b.add("networkTx")
+	diskRead, diskWrite := b.add("diskRead"), b.add("diskWrite")
+	b.run()
 
-		networkRxQuery := fmt.Sprintf(`sum(rate(node_network_receive_bytes_total{device!~"lo|docker.*|br-.*|veth.*"}[%s]))`, cfg.rateWin)
-		networkRxPoints, err = r.queryRange(ctx, networkRxQuery, start, now, cfg.step)
-		if err != nil {
-			networkRxPoints = []domain.MetricPoint{}
-		}
+	return &domain.MetricsRange{
+		CPU:          cpu.points(),
+		Memory:       mem.points(),
+		Disk:         disk.points(),
+		NetworkRx:    netRx.points(),
+		NetworkTx:    netTx.points(),
+		DiskRead:     diskRead.points(),
+		DiskWrite:    diskWrite.points(),
+		CPUThrottled: []domain.MetricPoint{},
+		OOMEvents:    []domain.MetricPoint{},
+		Warnings:     b.warnings,
+		Errors:       b.errors,
+	}, nil
+}
 
-		networkTxQuery := fmt.Sprintf(`sum(rate(node_network_transmit_bytes_total{device!~"lo|docker.*|br-.*|veth.*"}[%s]))`, cfg.rateWin)
-		networkTxPoints, err = r.queryRange(ctx, networkTxQuery, start, now, cfg.step)
-		if err != nil {
-			networkTxPoints = []domain.MetricPoint{}
-		}
+// GetPodUsage returns the current CPU (cores) and memory (bytes) usage of every pod
+// cAdvisor reports on.
+func (r *prometheusRepository) GetPodUsage(ctx context.Context) (*domain.PodUsageReport, error) {
+	if r.baseURL == "" {
+		return nil, fmt.Errorf("prometheus not configured")
+	}
 
-		diskReadQuery := fmt.Sprintf(`sum(rate(node_disk_read_bytes_total{device!~"loop.*|sr.*"}[%s]))`, cfg.rateWin)
-		diskReadPoints, err = r.queryRange(ctx, diskReadQuery, start, now, cfg.step)
-		if err != nil {
-			diskReadPoints = []domain.MetricPoint{}
-		}
+	b := r.newInstantBatch(ctx, domain.MetricScopeHost, catalogVars{rateWindow: instantRateWindow})
+	cpu, mem := b.add("cpuByPod"), b.add("memoryByPod")
+	b.run()
 
-		diskWriteQuery := fmt.Sprintf(`sum(rate(node_disk_written_bytes_total{device!~"loop.*|sr.*"}[%s]))`, cfg.rateWin)
-		diskWritePoints, err = r.queryRange(ctx, diskWriteQuery, start, now, cfg.step)
-		if err != nil {
-			diskWritePoints = []domain.MetricPoint{}
+	type podKey struct{ namespace, pod string }
+	usage := make(map[podKey]*domain.PodUsage)
+	var order []podKey
+	entry := func(labels map[string]string) *domain.PodUsage {
+		key := podKey{labels["namespace"], labels["pod"]}
+		u, ok := usage[key]
+		if !ok {
+			u = &domain.PodUsage{Namespace: key.namespace, Pod: key.pod}
+			usage[key] = u
+			order = append(order, key)
 		}
-	} else {
-		diskPoints = []domain.MetricPoint{}
-		networkRxPoints = []domain.MetricPoint{}
-		networkTxPoints = []domain.MetricPoint{}
-		diskReadPoints = []domain.MetricPoint{}
-		diskWritePoints = []domain.MetricPoint{}
+		return u
+	}
+	for _, s := range cpu.samples {
+		entry(s.labels).CPU = s.value
+	}
+	for _, s := range mem.samples {
+		entry(s.labels).Memory = s.value
 	}
 
-	return &domain.MetricsRange{
-		CPU:       cpuPoints,
-		Memory:    memPoints,
-		Disk:      diskPoints,
-		NetworkRx: networkRxPoints,
-		NetworkTx: networkTxPoints,
-		DiskRead:  diskReadPoints,
-		DiskWrite: diskWritePoints,
+	pods := make([]domain.PodUsage, 0, len(order))
+	for _, key := range order {
+		pods = append(pods, *usage[key])
+	}
+	return &domain.PodUsageReport{Pods: pods, Warnings: b.warnings, Errors: b.errors}, nil
+}
+
+// GetPodUsageRange returns CPU and memory series for every pod, labelled with
+// namespace and pod.
+func (r *prometheusRepository) GetPodUsageRange(ctx context.Context, rng domain.TimeRange) (*domain.PodUsageRange, error) {
+	if r.baseURL == "" {
+		return nil, fmt.Errorf("prometheus not configured")
+	}
+
+	vars := catalogVars{rateWindow: promDuration(rng.RateWindow())}
+	b := r.newRangeBatch(ctx, rng, domain.MetricScopeHost, vars)
+	cpu, mem := b.add("cpuByPod"), b.add("memoryByPod")
+	b.run()
+
+	return &domain.PodUsageRange{
+		CPU:      cpu.allSeries(),
+		Memory:   mem.allSeries(),
+		Warnings: b.warnings,
+		Errors:   b.errors,
 	}, nil
 }
 
-// GetNodeMetricsRange returns CPU, memory, network, and disk time-series for a specific k8s node.
-// The node parameter is the Kubernetes node name, which matches the `node` label added by
-// kube-prometheus-stack's node_exporter relabeling.
-func (r *prometheusRepository) GetNodeMetricsRange(ctx context.Context, node, duration string) (*domain.MetricsRange, error) {
+// topKindSuffix maps a TopQuery kind to the suffix of its host-scope catalog
+// entries, e.g. cpu by pod is "cpuByPod".
+var topKindSuffix = map[string]string{
+	domain.TopKindPod:  "ByPod",
+	domain.TopKindNode: "ByNode",
+}
+
+// GetTopConsumers ranks pods or nodes with topk over the per-pod or per-node
+// catalog entry for q.By. Pods in system namespaces are dropped, as in the
+// metrics-server ranking, so pods are fetched without topk and may exceed
+// q.Limit; the service cuts the list. Entries come back unsorted.
+func (r *prometheusRepository) GetTopConsumers(ctx context.Context, q domain.TopQuery) (*domain.TopConsumers, error) {
 	if r.baseURL == "" {
 		return nil, fmt.Errorf("prometheus not configured")
 	}
 
-	type rangeCfg struct {
-		window  time.Duration
-		step    string
-		rateWin string
+	suffix, ok := topKindSuffix[q.Kind]
+	if !ok {
+		return nil, fmt.Errorf("prometheus: cannot rank by kind %q", q.Kind)
+	}
+	name := q.By + suffix
+	def, ok := r.catalog.lookup(domain.MetricScopeHost, name)
+	if !ok {
+		return nil, fmt.Errorf("metric %q not in catalog for scope %q", name, domain.MetricScopeHost)
+	}
+	query, err := r.catalog.render(domain.MetricScopeHost, name, catalogVars{rateWindow: promDuration(q.Window)})
+	if err != nil {
+		return nil, err
+	}
+	if q.Limit > 0 && q.Kind != domain.TopKindPod {
+		query = fmt.Sprintf("topk(%d, %s)", q.Limit, query)
 	}
 
-	cfgs := map[string]rangeCfg{
-		"5m":  {5 * time.Minute, "10s", "1m"},
-		"15m": {15 * time.Minute, "15s", "1m"},
-		"1h":  {60 * time.Minute, "60s", "5m"},
-		"24h": {24 * time.Hour, "1440s", "15m"},
+	qctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+	samples, warnings, err := r.queryInstant(qctx, query)
+	if err != nil {
+		return nil, err
 	}
 
-	cfg, ok := cfgs[duration]
-	if !ok {
-		cfg = cfgs["5m"]
-	}
-
-	now := time.Now()
-	start := now.Add(-cfg.window)
-
-	cpuQuery := fmt.Sprintf(
-		`100 - (avg(rate(node_cpu_seconds_total{mode="idle",node=%q}[%s])) * 100)`,
-		node, cfg.rateWin,
-	)
-	memQuery := fmt.Sprintf(
-		`(1 - node_memory_MemAvailable_bytes{node=%q} / node_memory_MemTotal_bytes{node=%q}) * 100`,
-		node, node,
-	)
-	diskQuery := fmt.Sprintf(
-		`(1 - node_filesystem_avail_bytes{node=%q,mountpoint=~"/|/mnt/user|/mnt/cache",fstype!="tmpfs"} / node_filesystem_size_bytes{node=%q,mountpoint=~"/|/mnt/user|/mnt/cache",fstype!="tmpfs"}) * 100`,
-		node, node,
-	)
-	networkRxQuery := fmt.Sprintf(
-		`sum(rate(node_network_receive_bytes_total{node=%q,device!~"lo|docker.*|br-.*|veth.*"}[%s]))`,
-		node, cfg.rateWin,
-	)
-	networkTxQuery := fmt.Sprintf(
-		`sum(rate(node_network_transmit_bytes_total{node=%q,device!~"lo|docker.*|br-.*|veth.*"}[%s]))`,
-		node, cfg.rateWin,
-	)
-	diskReadQuery := fmt.Sprintf(
-		`sum(rate(node_disk_read_bytes_total{node=%q,device!~"loop.*|sr.*"}[%s]))`,
-		node, cfg.rateWin,
-	)
-	diskWriteQuery := fmt.Sprintf(
-		`sum(rate(node_disk_written_bytes_total{node=%q,device!~"loop.*|sr.*"}[%s]))`,
-		node, cfg.rateWin,
-	)
-
-	log.Printf("[prometheus] node range query node=%q duration=%s", node, duration)
-
-	runQuery := func(q string) []domain.MetricPoint {
-		pts, err := r.queryRange(ctx, q, start, now, cfg.step)
-		if err != nil {
-			log.Printf("[prometheus] node range query error: %v | query: %s", err, q)
-			return []domain.MetricPoint{}
+	entries := make([]domain.TopEntry, 0, len(samples))
+	for _, s := range samples {
+		entry := domain.TopEntry{Name: s.labels[q.Kind], Value: s.value}
+		if q.Kind == domain.TopKindPod {
+			entry.Namespace = s.labels["namespace"]
+			if domain.SystemNamespaces[entry.Namespace] {
+				continue
+			}
 		}
-		return pts
+		entries = append(entries, entry)
 	}
 
-	return &domain.MetricsRange{
-		CPU:       runQuery(cpuQuery),
-		Memory:    runQuery(memQuery),
-		Disk:      runQuery(diskQuery),
-		NetworkRx: runQuery(networkRxQuery),
-		NetworkTx: runQuery(networkTxQuery),
-		DiskRead:  runQuery(diskReadQuery),
-		DiskWrite: runQuery(diskWriteQuery),
+	return &domain.TopConsumers{
+		By:            q.By,
+		Kind:          q.Kind,
+		Unit:          def.Unit,
+		WindowSeconds: int64(q.Window / time.Second),
+		Source:        domain.TopSourcePrometheus,
+		Entries:       entries,
+		Warnings:      warnings,
 	}, nil
 }
 
-// queryInstant executes an instant Prometheus query and returns the first scalar result.
-func (r *prometheusRepository) queryInstant(ctx context.Context, query string) (float64, error) {
-	endpoint := fmt.Sprintf("%s/api/v1/query?query=%s", r.baseURL, url.QueryEscape(query))
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
-	if err != nil {
-		return 0, err
+// GetMetricsBreakdown returns per-mountpoint filesystem usage, per-interface network
+// rates and per-device disk I/O rates. An empty node covers every node; each series
+// keeps its node label so callers can tell nodes apart.
+func (r *prometheusRepository) GetMetricsBreakdown(ctx context.Context, node string, rng domain.TimeRange) (*domain.MetricsBreakdown, error) {
+	if r.baseURL == "" {
+		return nil, fmt.Errorf("prometheus not configured")
 	}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return 0, err
-	}
-	defer resp.Body.Close()
+	log.Printf("[prometheus] breakdown query node=%q start=%s end=%s step=%s", node, rng.Start.Format(time.RFC3339), rng.End.Format(time.RFC3339), rng.Step)
 
-	var result struct {
-		Status string `json:"status"`
-		Data   struct {
-			Result []struct {
-				Value []interface{} `json:"value"`
-			} `json:"result"`
-		} `json:"data"`
+	scope := domain.MetricScopeHost
+	if node != "" {
+		scope = domain.MetricScopeNode
 	}
+	vars := catalogVars{node: node, rateWindow: promDuration(rng.RateWindow())}
+	b := r.newRangeBatch(ctx, rng, scope, vars)
+	filesystems := b.add("filesystems")
+	netRx, netTx := b.add("networkRxByDevice"), b.add("networkTxByDevice")
+	diskRead, diskWrite := b.add("diskReadByDevice"), b.add("diskWriteByDevice")
+	b.run()
+
+	return &domain.MetricsBreakdown{
+		Filesystems: filesystems.allSeries(),
+		NetworkRx:   netRx.allSeries(),
+		NetworkTx:   netTx.allSeries(),
+		DiskRead:    diskRead.allSeries(),
+		DiskWrite:   diskWrite.allSeries(),
+		Warnings:    b.warnings,
+		Errors:      b.errors,
+	}, nil
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || result.Status != "success" {
-		return 0, fmt.Errorf("invalid prometheus response")
+// GetCapacityRange returns usage percent series for every filesystem, PVC and
+// node's memory, for capacity forecasting.
+func (r *prometheusRepository) GetCapacityRange(ctx context.Context, rng domain.TimeRange) (*domain.CapacityRange, error) {
+	if r.baseURL == "" {
+		return nil, fmt.Errorf("prometheus not configured")
 	}
-	if len(result.Data.Result) == 0 || len(result.Data.Result[0].Value) < 2 {
-		return 0, fmt.Errorf("no data")
+
+	vars := catalogVars{rateWindow: promDuration(rng.RateWindow())}
+	b := r.newRangeBatch(ctx, rng, domain.MetricScopeHost, vars)
+	filesystems, pvcs, memory := b.add("filesystems"), b.add("pvcUsage"), b.add("memoryByNode")
+	b.run()
+
+	return &domain.CapacityRange{
+		Filesystems: filesystems.allSeries(),
+		PVCs:        pvcs.allSeries(),
+		Memory:      memory.allSeries(),
+		Warnings:    b.warnings,
+		Errors:      b.errors,
+	}, nil
+}
+
+func (r *prometheusRepository) ListMetricDefinitions(ctx context.Context) ([]domain.MetricDefinition, error) {
+	return r.catalog.definitions(), nil
+}
+
+// GetCatalogMetricRange runs any catalog entry by scope and name. node is used by
+// node-scope entries and containerName ("namespace/pod-name") by pod-scope entries.
+func (r *prometheusRepository) GetCatalogMetricRange(ctx context.Context, scope, name, node, containerName string, rng domain.TimeRange) (*domain.CatalogMetricRange, error) {
+	if r.baseURL == "" {
+		return nil, fmt.Errorf("prometheus not configured")
 	}
 
-	valStr, ok := result.Data.Result[0].Value[1].(string)
+	def, ok := r.catalog.lookup(scope, name)
 	if !ok {
-		return 0, fmt.Errorf("unexpected value type")
+		return nil, fmt.Errorf("metric %q not in catalog for scope %q", name, scope)
 	}
-	return strconv.ParseFloat(valStr, 64)
-}
 
-// queryRange executes a Prometheus range query and returns time-series points.
-func (r *prometheusRepository) queryRange(ctx context.Context, query string, start, end time.Time, step string) ([]domain.MetricPoint, error) {
-	params := url.Values{}
-	params.Set("query", query)
-	params.Set("start", strconv.FormatInt(start.Unix(), 10))
-	params.Set("end", strconv.FormatInt(end.Unix(), 10))
-	params.Set("step", step)
+	vars := catalogVars{node: node, rateWindow: promDuration(rng.RateWindow())}
+	vars.namespace, vars.pod = splitContainerName(containerName)
 
-	endpoint := fmt.Sprintf("%s/api/v1/query_range?%s", r.baseURL, params.Encode())
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	query, err := r.catalog.render(scope, name, vars)
 	if err != nil {
 		return nil, err
 	}
+	def.Query = query
 
-	resp, err := http.DefaultClient.Do(req)
+	qctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+	series, warnings, err := r.queryRange(qctx, query, rng)
 	if err != nil {
-		log.Printf("[prometheus] queryRange HTTP error: %v | url: %s", err, endpoint)
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	var result struct {
-		Status string `json:"status"`
-		Data   struct {
-			Result []struct {
-				Values [][]interface{} `json:"values"`
-			} `json:"result"`
-		} `json:"data"`
-	}
+	return &domain.CatalogMetricRange{
+		MetricDefinition: def,
+		Series:           series,
+		Warnings:         warnings,
+	}, nil
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || result.Status != "success" {
-		log.Printf("[prometheus] queryRange bad response: status=%s decodeErr=%v | url: %s", result.Status, err, endpoint)
-		return nil, fmt.Errorf("invalid prometheus range response")
-	}
-	if len(result.Data.Result) == 0 {
-		return []domain.MetricPoint{}, nil
+// splitContainerName splits a "namespace/pod-name" ID. A bare pod name yields an
+// empty namespace.
+func splitContainerName(containerName string) (namespace, pod string) {
+	if ns, p, ok := strings.Cut(containerName, "/"); ok {
+		return ns, p
 	}
+	return "", containerName
+}
 
-	points := make([]domain.MetricPoint, 0, len(result.Data.Result[0].Values))
-	for _, pair := range result.Data.Result[0].Values {
-		if len(pair) < 2 {
-			continue
-		}
-		ts, ok := pair[0].(float64)
-		if !ok {
-			continue
-		}
-		valStr, ok := pair[1].(string)
-		if !ok {
-			continue
-		}
-		val, err := strconv.ParseFloat(valStr, 64)
-		if err != nil {
-			continue
-		}
-		points = append(points, domain.MetricPoint{
-			Time:  time.Unix(int64(ts), 0).Format("15:04:05"),
-			Value: val,
-		})
-	}
-	return points, nil
+// promDuration formats a duration as whole seconds, which every PromQL range
+// selector and the step parameter accept.
+func promDuration(d time.Duration) string {
+	return strconv.FormatInt(int64(d/time.Second), 10) + "s"
 }
@@ -0,0 +1,94 @@
+package resilience
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the backend while its breaker
+// is open.
+var ErrCircuitOpen = errors.New("circuit open")
+
+type breakerState int
+
+const (
+	stateClosed breakerState = iota
+	stateOpen
+	stateHalfOpen
+)
+
+// Breaker opens after Threshold consecutive failures and rejects calls for
+// Cooldown. It then lets a single probe through: success closes it again,
+// failure re-opens it for another cooldown.
+type Breaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func NewBreaker(name string, threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{name: name, threshold: threshold, cooldown: cooldown}
+}
+
+// Allow reports whether a call may proceed. Every allowed call must be
+// followed by Success or Failure.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case stateOpen:
+		wait := b.cooldown - time.Since(b.openedAt)
+		if wait > 0 {
+			return fmt.Errorf("%s: %w, retrying in %s", b.name, ErrCircuitOpen, wait.Round(100*time.Millisecond))
+		}
+		b.state = stateHalfOpen
+		b.probing = true
+		return nil
+	case stateHalfOpen:
+		if b.probing {
+			return fmt.Errorf("%s: %w, probe in flight", b.name, ErrCircuitOpen)
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = stateClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Release ends an allowed call without recording an outcome.
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == stateHalfOpen || b.failures >= b.threshold {
+		b.state = stateOpen
+		b.openedAt = time.Now()
+	}
+}
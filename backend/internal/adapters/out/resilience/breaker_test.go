@@ -0,0 +1,52 @@
+package resilience
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	const cooldown = 20 * time.Millisecond
+
+	// Each step is one call on the breaker: "allow" and "reject" call Allow
+	// and expect it to pass or fail; "fail", "ok" and "release" record an
+	// outcome; "wait" sleeps past the cooldown.
+	tests := []struct {
+		name  string
+		steps []string
+	}{
+		{name: "closed allows", steps: []string{"allow", "ok", "allow"}},
+		{name: "opens at the threshold", steps: []string{"allow", "fail", "allow", "fail", "allow", "fail", "reject"}},
+		{name: "success resets the count", steps: []string{"fail", "fail", "ok", "fail", "fail", "allow"}},
+		{name: "cooldown lets one probe through", steps: []string{"fail", "fail", "fail", "wait", "allow", "reject"}},
+		{name: "probe success closes", steps: []string{"fail", "fail", "fail", "wait", "allow", "ok", "allow", "fail", "allow"}},
+		{name: "probe failure reopens", steps: []string{"fail", "fail", "fail", "wait", "allow", "fail", "reject", "wait", "allow"}},
+		{name: "release frees the probe but stays half-open", steps: []string{"fail", "fail", "fail", "wait", "allow", "release", "allow", "fail", "reject"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBreaker("test", 3, cooldown)
+			for i, step := range tt.steps {
+				switch step {
+				case "allow":
+					if err := b.Allow(); err != nil {
+						t.Fatalf("step %d: Allow() = %v, want nil", i, err)
+					}
+				case "reject":
+					if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+						t.Fatalf("step %d: Allow() = %v, want ErrCircuitOpen", i, err)
+					}
+				case "fail":
+					b.Failure()
+				case "ok":
+					b.Success()
+				case "release":
+					b.Release()
+				case "wait":
+					time.Sleep(cooldown + 10*time.Millisecond)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+// Package resilience wraps outbound HTTP transports with retries and circuit
+// breakers so a struggling backend fails fast instead of stalling requests.
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultAttempts         = 3
+	defaultBaseDelay        = 100 * time.Millisecond
+	defaultMaxDelay         = 2 * time.Second
+	defaultFailureThreshold = 5
+	defaultCooldown         = 30 * time.Second
+)
+
+// Policy tunes retries and the circuit breaker. Zero values use defaults.
+type Policy struct {
+	// Attempts is the total number of tries for idempotent requests.
+	Attempts int
+	// BaseDelay and MaxDelay bound the exponential backoff between tries;
+	// each wait is drawn uniformly from zero to the current backoff.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// FailureThreshold consecutive failures open the breaker for Cooldown.
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+func (p Policy) withDefaults() Policy {
+	if p.Attempts <= 0 {
+		p.Attempts = defaultAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = defaultBaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = defaultMaxDelay
+	}
+	if p.FailureThreshold <= 0 {
+		p.FailureThreshold = defaultFailureThreshold
+	}
+	if p.Cooldown <= 0 {
+		p.Cooldown = defaultCooldown
+	}
+	return p
+}
+
+// Middleware returns a transport wrapper with its own breaker for the backend
+// called name. Use one per backend so one outage doesn't trip the others.
+func Middleware(name string, policy Policy) func(http.RoundTripper) http.RoundTripper {
+	policy = policy.withDefaults()
+	breaker := NewBreaker(name, policy.FailureThreshold, policy.Cooldown)
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &transport{name: name, policy: policy, breaker: breaker, next: next}
+	}
+}
+
+type transport struct {
+	name    string
+	policy  Policy
+	breaker *Breaker
+	next    http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempts := 1
+	if idempotent(req) {
+		attempts = t.policy.Attempts
+	}
+
+	backoff := t.policy.BaseDelay
+	for attempt := 1; ; attempt++ {
+		if err := t.breaker.Allow(); err != nil {
+			return nil, err
+		}
+
+		try, err := rewind(req, attempt)
+		if err != nil {
+			t.breaker.Release()
+			return nil, err
+		}
+		resp, err := t.next.RoundTrip(try)
+		switch {
+		case callerDone(req.Context(), err):
+			// The caller gave up or ran out of time; this says nothing about
+			// the backend. Release a half-open probe without closing the
+			// breaker.
+			t.breaker.Release()
+			return resp, err
+		case isFailure(resp, err):
+			t.breaker.Failure()
+		default:
+			t.breaker.Success()
+			return resp, err
+		}
+
+		if attempt >= attempts || req.Context().Err() != nil {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		wait := time.Duration(rand.Int64N(int64(backoff) + 1))
+		log.Printf("[%s] attempt %d/%d failed, retrying in %s", t.name, attempt, attempts, wait.Round(time.Millisecond))
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, t.policy.MaxDelay)
+	}
+}
+
+// idempotent reports whether a request can be sent again safely.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	}
+	return false
+}
+
+// rewind returns the request to send on the given attempt. Retries get a
+// fresh body from GetBody, since the previous attempt consumed the original.
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 1 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("rewind request body: %w", err)
+	}
+	try := req.Clone(req.Context())
+	try.Body = body
+	return try, nil
+}
+
+// callerDone reports whether an attempt ended because of the caller's own
+// context: cancelled, or past its deadline.
+func callerDone(ctx context.Context, err error) bool {
+	return ctx.Err() != nil || errors.Is(err, context.Canceled)
+}
+
+// isFailure reports whether the backend misbehaved: a transport error or a
+// 5xx/429 status.
+func isFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+}
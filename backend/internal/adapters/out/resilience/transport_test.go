@@ -0,0 +1,152 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedTransport answers with the given statuses in turn, repeating the
+// last one; a zero status is a transport error. It keeps each request body.
+type scriptedTransport struct {
+	statuses []int
+	calls    int
+	bodies   []string
+}
+
+func (s *scriptedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	status := s.statuses[min(s.calls, len(s.statuses)-1)]
+	s.calls++
+	if req.Body != nil {
+		body, _ := io.ReadAll(req.Body)
+		s.bodies = append(s.bodies, string(body))
+	}
+	if status == 0 {
+		return nil, errors.New("connection refused")
+	}
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+}
+
+func TestTransportRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		statuses   []int
+		wantCalls  int
+		wantStatus int // zero means an error
+	}{
+		{name: "success", method: http.MethodGet, statuses: []int{200}, wantCalls: 1, wantStatus: 200},
+		{name: "retries a 5xx", method: http.MethodGet, statuses: []int{503, 200}, wantCalls: 2, wantStatus: 200},
+		{name: "retries a transport error", method: http.MethodGet, statuses: []int{0, 0, 200}, wantCalls: 3, wantStatus: 200},
+		{name: "gives up after the attempts", method: http.MethodGet, statuses: []int{500}, wantCalls: 3, wantStatus: 500},
+		{name: "retries 429", method: http.MethodGet, statuses: []int{429, 204}, wantCalls: 2, wantStatus: 204},
+		{name: "4xx is not retried", method: http.MethodGet, statuses: []int{404}, wantCalls: 1, wantStatus: 404},
+		{name: "POST is not retried", method: http.MethodPost, statuses: []int{503}, wantCalls: 1, wantStatus: 503},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &scriptedTransport{statuses: tt.statuses}
+			rt := Middleware("test", Policy{BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})(next)
+
+			req, _ := http.NewRequest(tt.method, "http://backend/", nil)
+			resp, err := rt.RoundTrip(req)
+			status := 0
+			if err == nil {
+				status = resp.StatusCode
+			}
+			if status != tt.wantStatus {
+				t.Errorf("status = %d (err %v), want %d", status, err, tt.wantStatus)
+			}
+			if next.calls != tt.wantCalls {
+				t.Errorf("backend calls = %d, want %d", next.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestTransportOpensBreaker(t *testing.T) {
+	next := &scriptedTransport{statuses: []int{500}}
+	rt := Middleware("test", Policy{Attempts: 1, FailureThreshold: 2, Cooldown: time.Minute})(next)
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "http://backend/", nil)
+		if _, err := rt.RoundTrip(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req, _ := http.NewRequest(http.MethodGet, "http://backend/", nil)
+	if _, err := rt.RoundTrip(req); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("third call = %v, want ErrCircuitOpen", err)
+	}
+	if next.calls != 2 {
+		t.Errorf("backend calls = %d, want 2", next.calls)
+	}
+}
+
+func TestTransportRewindsBody(t *testing.T) {
+	next := &scriptedTransport{statuses: []int{503, 502, 200}}
+	rt := Middleware("test", Policy{BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})(next)
+
+	// NewRequest sets GetBody for a strings.Reader, making the GET retryable.
+	req, _ := http.NewRequest(http.MethodGet, "http://backend/search", strings.NewReader(`{"q":"up"}`))
+	if _, err := rt.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+	if len(next.bodies) != 3 {
+		t.Fatalf("backend got %d bodies, want 3", len(next.bodies))
+	}
+	for i, body := range next.bodies {
+		if body != `{"q":"up"}` {
+			t.Errorf("attempt %d body = %q, want the full body", i+1, body)
+		}
+	}
+}
+
+// slowTransport answers only when the request's context ends.
+type slowTransport struct{ calls int }
+
+func (s *slowTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.calls++
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func TestTransportIgnoresCallerContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		cancel func() (context.Context, context.CancelFunc)
+	}{
+		{name: "deadline", cancel: func() (context.Context, context.CancelFunc) {
+			return context.WithTimeout(context.Background(), 5*time.Millisecond)
+		}},
+		{name: "cancel", cancel: func() (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(5*time.Millisecond, cancel)
+			return ctx, cancel
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &slowTransport{}
+			rt := Middleware("test", Policy{FailureThreshold: 1, Cooldown: time.Minute})(next)
+
+			for i := 0; i < 3; i++ {
+				ctx, cancel := tt.cancel()
+				req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://backend/", nil)
+				_, err := rt.RoundTrip(req)
+				cancel()
+				if errors.Is(err, ErrCircuitOpen) {
+					t.Fatalf("call %d: breaker opened on the caller's own context", i)
+				}
+			}
+			// Each call gives up on its first attempt rather than retrying.
+			if next.calls != 3 {
+				t.Errorf("backend calls = %d, want 3", next.calls)
+			}
+		})
+	}
+}
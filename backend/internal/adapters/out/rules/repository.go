@@ -0,0 +1,284 @@
+// Package rules is an OverwatchRepository that needs no external service: it
+// diagnoses the cluster with deterministic rules over pod and node state and,
+// when available, node disk usage.
+package rules
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+	portout "github.com/isaacwallace123/portfolio-infra/internal/core/ports/out"
+)
+
+const (
+	defaultRestartThreshold      = 5
+	defaultProbeFailureThreshold = 3
+	defaultDiskWarnPercent       = 85
+	defaultDiskCriticalPercent   = 95
+	defaultHistoryInterval       = 5 * time.Minute
+	defaultHistorySize           = 288
+)
+
+// Config tunes the rules. Zero fields take the defaults above.
+type Config struct {
+	// RestartThreshold is the restart count at which a pod is flagged.
+	RestartThreshold int32
+	// ProbeFailureThreshold is how many failed probes in the last hour flag a pod.
+	ProbeFailureThreshold int32
+	// DiskWarnPercent and DiskCriticalPercent flag node disk usage.
+	DiskWarnPercent     float64
+	DiskCriticalPercent float64
+	// HistoryInterval is the least time between insights kept for history,
+	// and HistorySize how many are kept.
+	HistoryInterval time.Duration
+	HistorySize     int
+}
+
+type rulesRepository struct {
+	cluster portout.ClusterRepository
+	metrics portout.MetricsRepository
+	cfg     Config
+
+	mu      sync.Mutex
+	history []domain.OverwatchInsight // oldest first
+}
+
+// NewOverwatchRepository returns an analyzer over cluster state. metrics may
+// be nil, in which case disk usage isn't checked.
+func NewOverwatchRepository(cluster portout.ClusterRepository, metrics portout.MetricsRepository, cfg Config) portout.OverwatchRepository {
+	if cfg.RestartThreshold <= 0 {
+		cfg.RestartThreshold = defaultRestartThreshold
+	}
+	if cfg.ProbeFailureThreshold <= 0 {
+		cfg.ProbeFailureThreshold = defaultProbeFailureThreshold
+	}
+	if cfg.DiskWarnPercent <= 0 {
+		cfg.DiskWarnPercent = defaultDiskWarnPercent
+	}
+	if cfg.DiskCriticalPercent <= 0 {
+		cfg.DiskCriticalPercent = defaultDiskCriticalPercent
+	}
+	if cfg.HistoryInterval <= 0 {
+		cfg.HistoryInterval = defaultHistoryInterval
+	}
+	if cfg.HistorySize <= 0 {
+		cfg.HistorySize = defaultHistorySize
+	}
+	return &rulesRepository{cluster: cluster, metrics: metrics, cfg: cfg}
+}
+
+func (r *rulesRepository) GetInsights(ctx context.Context) (*domain.OverwatchInsight, error) {
+	pods, err := r.cluster.ListPodDiagnostics(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("rules: list pods: %w", err)
+	}
+	nodes, err := r.cluster.ListNodes(ctx)
+	if err != nil {
+		log.Printf("[rules] list nodes: %v", err)
+	}
+	disk := r.nodeDisk(ctx)
+
+	var findings []finding
+	for _, p := range pods {
+		findings = append(findings, podFindings(p, r.cfg)...)
+	}
+	for _, n := range nodes {
+		findings = append(findings, nodeFindings(n, disk[n.Name], r.cfg)...)
+	}
+	sortFindings(findings)
+
+	now := time.Now().UTC()
+	insight := &domain.OverwatchInsight{
+		CollectedAt:     &now,
+		Status:          status(findings),
+		Summary:         fmt.Sprintf("Rule-based analysis of %d pods and %d nodes found no issues.", len(pods), len(nodes)),
+		Anomalies:       make([]domain.OverwatchAnomaly, 0, len(findings)),
+		Recommendations: []string{},
+		Source:          domain.InsightSourceRules,
+	}
+	seen := make(map[string]bool)
+	for _, f := range findings {
+		insight.Anomalies = append(insight.Anomalies, f.anomaly())
+		if s := f.suggestions[0]; !seen[s] {
+			seen[s] = true
+			insight.Recommendations = append(insight.Recommendations, s)
+		}
+	}
+	if len(findings) > 0 {
+		insight.Summary = fmt.Sprintf("Rule-based analysis of %d pods and %d nodes found %d issues.", len(pods), len(nodes), len(findings))
+	}
+
+	r.record(*insight)
+	return insight, nil
+}
+
+// nodeDisk returns each node's disk usage percent. It is best effort: nodes
+// are missing when metrics are unavailable.
+func (r *rulesRepository) nodeDisk(ctx context.Context) map[string]*float64 {
+	disk := make(map[string]*float64)
+	if r.metrics == nil {
+		return disk
+	}
+	metrics, err := r.metrics.GetNodeMetrics(ctx)
+	if err != nil {
+		log.Printf("[rules] node metrics: %v", err)
+		return disk
+	}
+	for _, n := range metrics.Nodes {
+		disk[n.Name] = n.Disk
+	}
+	return disk
+}
+
+func (r *rulesRepository) GetPodInsights(ctx context.Context, namespace, app string) (*domain.PodInsight, error) {
+	pods, err := r.cluster.ListPodDiagnostics(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("rules: list pods: %w", err)
+	}
+	var matched []domain.PodDiagnostics
+	for _, p := range pods {
+		if p.Namespace == namespace && p.App == app {
+			matched = append(matched, p)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("rules: no pods found for %s/%s", namespace, app)
+	}
+	insight := r.diagnose(namespace, app, matched)
+	return &insight, nil
+}
+
+func (r *rulesRepository) GetAllPodInsights(ctx context.Context) ([]domain.PodInsight, error) {
+	pods, err := r.cluster.ListPodDiagnostics(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("rules: list pods: %w", err)
+	}
+	byApp := make(map[string][]domain.PodDiagnostics)
+	for _, p := range pods {
+		key := p.Namespace + "/" + p.App
+		byApp[key] = append(byApp[key], p)
+	}
+	keys := make([]string, 0, len(byApp))
+	for k := range byApp {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	insights := make([]domain.PodInsight, 0, len(keys))
+	for _, k := range keys {
+		namespace, app, _ := strings.Cut(k, "/")
+		insights = append(insights, r.diagnose(namespace, app, byApp[k]))
+	}
+	return insights, nil
+}
+
+// diagnose turns the findings for an app's pods into a pod insight; the
+// most severe finding gives the root cause.
+func (r *rulesRepository) diagnose(namespace, app string, pods []domain.PodDiagnostics) domain.PodInsight {
+	var findings []finding
+	for _, p := range pods {
+		findings = append(findings, podFindings(p, r.cfg)...)
+	}
+	sortFindings(findings)
+
+	insight := domain.PodInsight{
+		Namespace:   namespace,
+		App:         app,
+		AnalyzedAt:  time.Now().UTC(),
+		Status:      status(findings),
+		Diagnosis:   fmt.Sprintf("All %d pods of %s are running without issues.", len(pods), app),
+		Suggestions: []string{},
+	}
+	if len(findings) == 0 {
+		return insight
+	}
+
+	descriptions := make([]string, len(findings))
+	seen := make(map[string]bool)
+	for i, f := range findings {
+		descriptions[i] = f.description
+		for _, s := range f.suggestions {
+			if !seen[s] {
+				seen[s] = true
+				insight.Suggestions = append(insight.Suggestions, s)
+			}
+		}
+	}
+	insight.Diagnosis = strings.Join(descriptions, "; ") + "."
+	insight.RootCause = findings[0].rootCause
+	return insight
+}
+
+// GetHistory returns the insights this process has produced in the query's
+// range, newest first, up to one more than the query's limit so the caller
+// can tell whether another page follows.
+func (r *rulesRepository) GetHistory(ctx context.Context, q domain.HistoryQuery) ([]domain.OverwatchInsight, error) {
+	upper, err := q.Upper()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := []domain.OverwatchInsight{}
+	for i := len(r.history) - 1; i >= 0; i-- {
+		in := r.history[i]
+		if !upper.IsZero() && in.CollectedAt.After(upper) {
+			continue
+		}
+		if !q.From.IsZero() && in.CollectedAt.Before(q.From) {
+			break
+		}
+		result = append(result, cloneInsight(in))
+		if q.Limit > 0 && len(result) > q.Limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// record keeps an insight for history unless the last one kept is more
+// recent than HistoryInterval.
+func (r *rulesRepository) record(insight domain.OverwatchInsight) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n := len(r.history); n > 0 && insight.CollectedAt.Sub(*r.history[n-1].CollectedAt) < r.cfg.HistoryInterval {
+		return
+	}
+	r.history = append(r.history, cloneInsight(insight))
+	if over := len(r.history) - r.cfg.HistorySize; over > 0 {
+		r.history = append(r.history[:0:0], r.history[over:]...)
+	}
+}
+
+// cloneInsight copies an insight's slices, so that the service annotating
+// anomalies in one copy never touches another.
+func cloneInsight(in domain.OverwatchInsight) domain.OverwatchInsight {
+	in.Anomalies = append([]domain.OverwatchAnomaly(nil), in.Anomalies...)
+	in.Recommendations = append([]string(nil), in.Recommendations...)
+	return in
+}
+
+func sortFindings(findings []finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		return domain.SeverityRank(findings[i].severity) > domain.SeverityRank(findings[j].severity)
+	})
+}
+
+// status maps the most severe finding onto an insight status; findings must
+// already be sorted.
+func status(findings []finding) string {
+	switch {
+	case len(findings) == 0:
+		return "healthy"
+	case findings[0].severity == "high":
+		return "critical"
+	}
+	return "warning"
+}
@@ -0,0 +1,92 @@
+package rules
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+var historyBase = time.Unix(1_700_000_000, 0).UTC()
+
+func newTestRepository() *rulesRepository {
+	return NewOverwatchRepository(nil, nil, Config{}).(*rulesRepository)
+}
+
+// recordAt records insights collected the given minutes after historyBase.
+func recordAt(r *rulesRepository, minutes ...int) {
+	for _, m := range minutes {
+		at := historyBase.Add(time.Duration(m) * time.Minute)
+		r.record(domain.OverwatchInsight{CollectedAt: &at, Anomalies: []domain.OverwatchAnomaly{{Type: "crash_loop"}}})
+	}
+}
+
+func minutesOf(items []domain.OverwatchInsight) []int {
+	out := make([]int, len(items))
+	for i, in := range items {
+		out[i] = int(in.CollectedAt.Sub(historyBase) / time.Minute)
+	}
+	return out
+}
+
+func TestRecordKeepsOnePerInterval(t *testing.T) {
+	r := newTestRepository()
+	// The default interval is 5 minutes.
+	recordAt(r, 0, 2, 5, 9, 10)
+	got, err := r.GetHistory(context.Background(), domain.HistoryQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{10, 5, 0}; !slices.Equal(minutesOf(got), want) {
+		t.Errorf("history = %v, want %v", minutesOf(got), want)
+	}
+}
+
+func TestGetHistory(t *testing.T) {
+	r := newTestRepository()
+	recordAt(r, 0, 5, 10, 15, 20)
+	minute := func(m int) time.Time { return historyBase.Add(time.Duration(m) * time.Minute) }
+
+	tests := []struct {
+		name string
+		q    domain.HistoryQuery
+		want []int
+	}{
+		{name: "everything", q: domain.HistoryQuery{}, want: []int{20, 15, 10, 5, 0}},
+		// One more than the limit tells the caller another page follows.
+		{name: "limit plus one", q: domain.HistoryQuery{Limit: 2}, want: []int{20, 15, 10}},
+		{name: "last page", q: domain.HistoryQuery{Limit: 2, Cursor: domain.HistoryCursor(minute(5))}, want: []int{0}},
+		{name: "cursor", q: domain.HistoryQuery{Limit: 2, Cursor: domain.HistoryCursor(minute(15))}, want: []int{10, 5, 0}},
+		{name: "range", q: domain.HistoryQuery{From: minute(5), To: minute(15)}, want: []int{15, 10, 5}},
+		{name: "exact fit", q: domain.HistoryQuery{Limit: 5}, want: []int{20, 15, 10, 5, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.GetHistory(context.Background(), tt.q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(minutesOf(got), tt.want) {
+				t.Errorf("history = %v, want %v", minutesOf(got), tt.want)
+			}
+		})
+	}
+
+	if _, err := r.GetHistory(context.Background(), domain.HistoryQuery{Cursor: "x"}); err == nil {
+		t.Error("want an error for a bad cursor")
+	}
+}
+
+func TestGetHistoryReturnsCopies(t *testing.T) {
+	r := newTestRepository()
+	recordAt(r, 0)
+
+	first, _ := r.GetHistory(context.Background(), domain.HistoryQuery{})
+	first[0].Anomalies[0].Hidden = true
+	second, _ := r.GetHistory(context.Background(), domain.HistoryQuery{})
+	if second[0].Anomalies[0].Hidden {
+		t.Error("changing a returned insight changed the stored history")
+	}
+}
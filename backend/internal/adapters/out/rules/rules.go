@@ -0,0 +1,281 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+// finding is one rule that fired. It becomes an anomaly in cluster insights
+// and feeds the diagnosis of the pod's app.
+type finding struct {
+	severity    string
+	kind        string
+	affected    string
+	description string
+	rootCause   string
+	suggestions []string
+}
+
+func (f finding) anomaly() domain.OverwatchAnomaly {
+	return domain.OverwatchAnomaly{
+		Severity:    f.severity,
+		Type:        f.kind,
+		Description: f.description,
+		Affected:    f.affected,
+	}
+}
+
+var imagePullReasons = map[string]bool{
+	"ImagePullBackOff":  true,
+	"ErrImagePull":      true,
+	"InvalidImageName":  true,
+	"ErrImageNeverPull": true,
+}
+
+var configErrorReasons = map[string]bool{
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+// podFindings applies the pod rules: unschedulable, image pull errors,
+// container config errors, CrashLoopBackOff, OOMKilled, failing probes and
+// high restart counts. A pod whose restarts are already explained by a crash
+// loop or OOM kill isn't flagged for restarts as well.
+func podFindings(d domain.PodDiagnostics, cfg Config) []finding {
+	id := d.Namespace + "/" + d.Pod
+	var out []finding
+
+	if d.Unschedulable != "" {
+		out = append(out, finding{
+			severity:    "high",
+			kind:        "pod_unschedulable",
+			affected:    id,
+			description: fmt.Sprintf("%s is pending and can't be scheduled", id),
+			rootCause:   "The scheduler can't place the pod: " + d.Unschedulable,
+			suggestions: schedulingSuggestions(d.Unschedulable),
+		})
+	}
+
+	restartsExplained := false
+	for _, c := range d.Containers {
+		switch {
+		case imagePullReasons[c.Waiting]:
+			out = append(out, finding{
+				severity:    "high",
+				kind:        "image_pull_error",
+				affected:    id,
+				description: fmt.Sprintf("%s container %s can't pull its image (%s)", id, c.Name, c.Waiting),
+				rootCause:   orDefault(c.WaitingMessage, "The container image can't be pulled."),
+				suggestions: []string{
+					"Check the image name and tag exist in the registry",
+					"For a private registry, check the pod's imagePullSecrets",
+					"Check the node can reach the registry",
+				},
+			})
+		case configErrorReasons[c.Waiting]:
+			out = append(out, finding{
+				severity:    "high",
+				kind:        "container_config_error",
+				affected:    id,
+				description: fmt.Sprintf("%s container %s can't be created (%s)", id, c.Name, c.Waiting),
+				rootCause:   orDefault(c.WaitingMessage, "The container's configuration is invalid."),
+				suggestions: []string{
+					"Check every ConfigMap and Secret the pod references exists in its namespace",
+					"Check environment variables and volume mounts refer to existing keys",
+				},
+			})
+		case c.Waiting == "CrashLoopBackOff":
+			restartsExplained = true
+			suggestions := []string{fmt.Sprintf("Read the previous run's logs: kubectl logs -n %s %s -c %s --previous", d.Namespace, d.Pod, c.Name)}
+			if c.LastTerminated == "OOMKilled" {
+				suggestions = append([]string{"Raise the container's memory limit or reduce its memory use"}, suggestions...)
+			} else {
+				suggestions = append(suggestions, "Check the container's command, configuration and the services it needs at startup")
+			}
+			out = append(out, finding{
+				severity:    "high",
+				kind:        "crash_loop",
+				affected:    id,
+				description: fmt.Sprintf("%s container %s is in CrashLoopBackOff after %d restarts", id, c.Name, c.Restarts),
+				rootCause:   exitCause(c),
+				suggestions: suggestions,
+			})
+		case c.LastTerminated == "OOMKilled":
+			restartsExplained = true
+			severity := "medium"
+			if !d.Ready {
+				severity = "high"
+			}
+			out = append(out, finding{
+				severity:    severity,
+				kind:        "oom_killed",
+				affected:    id,
+				description: fmt.Sprintf("%s container %s was OOMKilled", id, c.Name),
+				rootCause:   exitCause(c),
+				suggestions: []string{
+					"Raise the container's memory limit or reduce its memory use",
+					"Compare memory usage with the limit over time to size it",
+				},
+			})
+		}
+	}
+
+	if d.ProbeFailures >= cfg.ProbeFailureThreshold {
+		severity := "medium"
+		if !d.Ready {
+			severity = "high"
+		}
+		out = append(out, finding{
+			severity:    severity,
+			kind:        "probe_failure",
+			affected:    id,
+			description: fmt.Sprintf("%s failed %d health probes in the last hour", id, d.ProbeFailures),
+			rootCause:   orDefault(d.ProbeMessage, "Liveness or readiness probes are failing."),
+			suggestions: []string{
+				"Check the probe's path and port match what the container serves",
+				"If the app starts slowly, raise initialDelaySeconds or add a startupProbe",
+				"If the endpoint is slow under load, raise the probe's timeoutSeconds",
+			},
+		})
+	}
+
+	if !restartsExplained && d.Restarts >= cfg.RestartThreshold {
+		severity := "medium"
+		if d.Restarts >= 4*cfg.RestartThreshold {
+			severity = "high"
+		}
+		out = append(out, finding{
+			severity:    severity,
+			kind:        "high_restarts",
+			affected:    id,
+			description: fmt.Sprintf("%s has restarted %d times", id, d.Restarts),
+			rootCause:   restartCause(d),
+			suggestions: []string{
+				fmt.Sprintf("Read the previous run's logs: kubectl logs -n %s %s --previous", d.Namespace, d.Pod),
+				"Check whether the restarts line up with liveness probe failures or node events",
+			},
+		})
+	}
+	return out
+}
+
+// schedulingSuggestions reads the scheduler's message for the usual reasons
+// a pod can't be placed.
+func schedulingSuggestions(message string) []string {
+	m := strings.ToLower(message)
+	var out []string
+	if strings.Contains(m, "insufficient") {
+		out = append(out, "Lower the pod's resource requests or add node capacity")
+	}
+	if strings.Contains(m, "taint") {
+		out = append(out, "Add a toleration for the node taints or remove them")
+	}
+	if strings.Contains(m, "affinity") || strings.Contains(m, "selector") {
+		out = append(out, "Check the pod's nodeSelector and affinity rules match at least one node")
+	}
+	if strings.Contains(m, "persistentvolumeclaim") || strings.Contains(m, "volume") {
+		out = append(out, "Check the pod's PersistentVolumeClaims are bound and in the node's zone")
+	}
+	if len(out) == 0 {
+		out = append(out, "Describe the pod to see the scheduler's events")
+	}
+	return out
+}
+
+func exitCause(c domain.ContainerDiagnostics) string {
+	switch {
+	case c.LastTerminated == "OOMKilled":
+		return "The container is killed for exceeding its memory limit."
+	case c.LastExitCode == 137:
+		return "The container was killed (exit code 137), often by the OOM killer or a failed liveness probe."
+	case c.LastExitCode == 143:
+		return "The container was terminated (exit code 143)."
+	case c.LastExitCode == 126 || c.LastExitCode == 127:
+		return fmt.Sprintf("The container's command can't be run (exit code %d); check the entrypoint and image.", c.LastExitCode)
+	case c.LastExitCode != 0:
+		return fmt.Sprintf("The container exits with code %d (%s).", c.LastExitCode, orDefault(c.LastTerminated, "Error"))
+	}
+	return "The container keeps exiting shortly after it starts."
+}
+
+func restartCause(d domain.PodDiagnostics) string {
+	for _, c := range d.Containers {
+		if c.LastTerminated != "" {
+			return exitCause(c)
+		}
+	}
+	return "The pod's containers keep restarting."
+}
+
+var nodePressure = map[string]struct {
+	severity   string
+	kind       string
+	cause      string
+	suggestion string
+}{
+	"MemoryPressure": {"high", "node_memory_pressure", "The node is low on memory and may evict pods.",
+		"Move or limit memory-heavy pods on the node, or add memory"},
+	"DiskPressure": {"high", "node_disk_pressure", "The node is low on disk space and may evict pods.",
+		"Free disk space: prune unused images and logs, or grow the disk"},
+	"PIDPressure": {"medium", "node_pid_pressure", "The node is running out of process IDs.",
+		"Find the pods spawning many processes and set pod PID limits"},
+	"NetworkUnavailable": {"high", "node_network_unavailable", "The node's network is not configured.",
+		"Check the CNI plugin pods on the node"},
+}
+
+// nodeFindings applies the node rules: not ready, pressure conditions and
+// disk usage. disk is the node's disk usage percent, nil when unknown.
+func nodeFindings(n domain.NodeInfo, disk *float64, cfg Config) []finding {
+	var out []finding
+	if n.Status != "Ready" {
+		out = append(out, finding{
+			severity:    "high",
+			kind:        "node_not_ready",
+			affected:    n.Name,
+			description: fmt.Sprintf("Node %s is %s", n.Name, n.Status),
+			rootCause:   "The kubelet on the node isn't reporting ready.",
+			suggestions: []string{
+				"Check the node is powered on and reachable",
+				"Check the kubelet and container runtime on the node",
+			},
+		})
+	}
+	for _, p := range n.Pressure {
+		rule, ok := nodePressure[p]
+		if !ok {
+			continue
+		}
+		out = append(out, finding{
+			severity:    rule.severity,
+			kind:        rule.kind,
+			affected:    n.Name,
+			description: fmt.Sprintf("Node %s reports %s", n.Name, p),
+			rootCause:   rule.cause,
+			suggestions: []string{rule.suggestion},
+		})
+	}
+	if disk != nil && *disk >= cfg.DiskWarnPercent {
+		severity, kind := "medium", "disk_near_full"
+		if *disk >= cfg.DiskCriticalPercent {
+			severity, kind = "high", "disk_full"
+		}
+		out = append(out, finding{
+			severity:    severity,
+			kind:        kind,
+			affected:    n.Name,
+			description: fmt.Sprintf("Node %s disk is %.0f%% full", n.Name, *disk),
+			rootCause:   "The node's filesystem is nearly out of space.",
+			suggestions: []string{"Free disk space: prune unused images and logs, or grow the disk"},
+		})
+	}
+	return out
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
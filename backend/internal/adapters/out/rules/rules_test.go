@@ -0,0 +1,167 @@
+package rules
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+var testConfig = Config{RestartThreshold: 5, ProbeFailureThreshold: 3, DiskWarnPercent: 85, DiskCriticalPercent: 95}
+
+// kinds lists "severity kind" for each finding, in order.
+func kinds(findings []finding) []string {
+	out := make([]string, len(findings))
+	for i, f := range findings {
+		out[i] = f.severity + " " + f.kind
+	}
+	return out
+}
+
+func TestPodFindings(t *testing.T) {
+	pod := func(ready bool, restarts int32, containers ...domain.ContainerDiagnostics) domain.PodDiagnostics {
+		return domain.PodDiagnostics{Namespace: "media", Pod: "jellyfin-0", Ready: ready, Restarts: restarts, Containers: containers}
+	}
+	tests := []struct {
+		name string
+		pod  domain.PodDiagnostics
+		want []string
+	}{
+		{name: "healthy", pod: pod(true, 0, domain.ContainerDiagnostics{Name: "app"})},
+		{name: "restarts below threshold", pod: pod(true, 4)},
+		{name: "restarts at threshold", pod: pod(true, 5), want: []string{"medium high_restarts"}},
+		{name: "restarts far over threshold", pod: pod(true, 20), want: []string{"high high_restarts"}},
+		{
+			name: "crash loop explains restarts",
+			pod:  pod(false, 30, domain.ContainerDiagnostics{Name: "app", Restarts: 30, Waiting: "CrashLoopBackOff", LastExitCode: 1}),
+			want: []string{"high crash_loop"},
+		},
+		{
+			name: "OOM kill explains restarts",
+			pod:  pod(true, 8, domain.ContainerDiagnostics{Name: "app", Restarts: 8, LastTerminated: "OOMKilled"}),
+			want: []string{"medium oom_killed"},
+		},
+		{
+			name: "OOM kill on an unready pod",
+			pod:  pod(false, 1, domain.ContainerDiagnostics{Name: "app", LastTerminated: "OOMKilled"}),
+			want: []string{"high oom_killed"},
+		},
+		{
+			name: "image pull error",
+			pod:  pod(false, 0, domain.ContainerDiagnostics{Name: "app", Waiting: "ImagePullBackOff"}),
+			want: []string{"high image_pull_error"},
+		},
+		{
+			name: "config error",
+			pod:  pod(false, 0, domain.ContainerDiagnostics{Name: "app", Waiting: "CreateContainerConfigError"}),
+			want: []string{"high container_config_error"},
+		},
+		{
+			name: "unschedulable",
+			pod:  domain.PodDiagnostics{Namespace: "media", Pod: "jellyfin-0", Unschedulable: "0/3 nodes are available: 3 Insufficient memory."},
+			want: []string{"high pod_unschedulable"},
+		},
+		{
+			name: "probe failures on a ready pod",
+			pod:  domain.PodDiagnostics{Namespace: "media", Pod: "jellyfin-0", Ready: true, ProbeFailures: 3},
+			want: []string{"medium probe_failure"},
+		},
+		{
+			name: "few probe failures",
+			pod:  domain.PodDiagnostics{Namespace: "media", Pod: "jellyfin-0", Ready: true, ProbeFailures: 2},
+		},
+		{
+			name: "one container crashing, another restarting",
+			pod: pod(false, 12,
+				domain.ContainerDiagnostics{Name: "app", Waiting: "CrashLoopBackOff"},
+				domain.ContainerDiagnostics{Name: "sidecar", Restarts: 6}),
+			want: []string{"high crash_loop"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := podFindings(tt.pod, testConfig)
+			if !slices.Equal(kinds(got), tt.want) {
+				t.Errorf("findings = %v, want %v", kinds(got), tt.want)
+			}
+			for _, f := range got {
+				if f.affected != "media/jellyfin-0" || len(f.suggestions) == 0 || f.rootCause == "" {
+					t.Errorf("incomplete finding %+v", f)
+				}
+			}
+		})
+	}
+}
+
+func TestSchedulingSuggestions(t *testing.T) {
+	tests := []struct {
+		message string
+		want    int
+	}{
+		{message: "0/3 nodes are available: 3 Insufficient cpu.", want: 1},
+		{message: "0/2 nodes are available: 1 node(s) had untolerated taint, 1 node(s) didn't match Pod's node affinity/selector.", want: 2},
+		{message: "pod has unbound immediate PersistentVolumeClaims", want: 1},
+		{message: "something new", want: 1},
+	}
+	for _, tt := range tests {
+		if got := schedulingSuggestions(tt.message); len(got) != tt.want {
+			t.Errorf("schedulingSuggestions(%q) = %v, want %d suggestions", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestNodeFindings(t *testing.T) {
+	disk := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name string
+		node domain.NodeInfo
+		disk *float64
+		want []string
+	}{
+		{name: "healthy", node: domain.NodeInfo{Name: "node-1", Status: "Ready"}, disk: disk(50)},
+		{name: "disk unknown", node: domain.NodeInfo{Name: "node-1", Status: "Ready"}},
+		{name: "disk just under warn", node: domain.NodeInfo{Name: "node-1", Status: "Ready"}, disk: disk(84.9)},
+		{name: "disk at warn", node: domain.NodeInfo{Name: "node-1", Status: "Ready"}, disk: disk(85), want: []string{"medium disk_near_full"}},
+		{name: "disk just under critical", node: domain.NodeInfo{Name: "node-1", Status: "Ready"}, disk: disk(94.9), want: []string{"medium disk_near_full"}},
+		{name: "disk at critical", node: domain.NodeInfo{Name: "node-1", Status: "Ready"}, disk: disk(95), want: []string{"high disk_full"}},
+		{name: "not ready", node: domain.NodeInfo{Name: "node-1", Status: "NotReady"}, want: []string{"high node_not_ready"}},
+		{
+			name: "pressure conditions",
+			node: domain.NodeInfo{Name: "node-1", Status: "Ready", Pressure: []string{"DiskPressure", "PIDPressure", "Unknown"}},
+			want: []string{"high node_disk_pressure", "medium node_pid_pressure"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nodeFindings(tt.node, tt.disk, testConfig)
+			if !slices.Equal(kinds(got), tt.want) {
+				t.Errorf("findings = %v, want %v", kinds(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		severities []string
+		want       string
+	}{
+		{name: "no findings", want: "healthy"},
+		{name: "low only", severities: []string{"low"}, want: "warning"},
+		{name: "medium", severities: []string{"low", "medium"}, want: "warning"},
+		{name: "any high", severities: []string{"medium", "high", "low"}, want: "critical"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := make([]finding, len(tt.severities))
+			for i, s := range tt.severities {
+				findings[i].severity = s
+			}
+			sortFindings(findings)
+			if got := status(findings); got != tt.want {
+				t.Errorf("status = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
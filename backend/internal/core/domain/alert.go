@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// Alert is a single Alertmanager alert. ContainerID ("namespace/pod-name") and
+// Node are filled from the alert's labels when it targets a pod or node, so
+// alerts can be shown next to the affected resource.
+type Alert struct {
+	Fingerprint  string            `json:"fingerprint"`
+	Name         string            `json:"name"`
+	Severity     string            `json:"severity,omitempty"`
+	State        string            `json:"state"`
+	Summary      string            `json:"summary,omitempty"`
+	Description  string            `json:"description,omitempty"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+	SilencedBy   []string          `json:"silencedBy"`
+	InhibitedBy  []string          `json:"inhibitedBy"`
+	ContainerID  string            `json:"containerId,omitempty"`
+	Node         string            `json:"node,omitempty"`
+}
+
+// AlertGroup is a set of alerts Alertmanager routes together.
+type AlertGroup struct {
+	Labels   map[string]string `json:"labels"`
+	Receiver string            `json:"receiver"`
+	Alerts   []Alert           `json:"alerts"`
+}
+
+// SilenceMatcher matches one label. IsEqual false negates the match; like
+// Alertmanager, a missing IsEqual means true.
+type SilenceMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual *bool  `json:"isEqual,omitempty"`
+}
+
+// Silence mutes alerts matching every matcher between StartsAt and EndsAt.
+// ID and State are set by Alertmanager.
+type Silence struct {
+	ID        string           `json:"id,omitempty"`
+	State     string           `json:"state,omitempty"`
+	Matchers  []SilenceMatcher `json:"matchers"`
+	StartsAt  time.Time        `json:"startsAt"`
+	EndsAt    time.Time        `json:"endsAt"`
+	CreatedBy string           `json:"createdBy"`
+	Comment   string           `json:"comment"`
+	UpdatedAt *time.Time       `json:"updatedAt,omitempty"`
+}
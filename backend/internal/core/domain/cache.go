@@ -0,0 +1,22 @@
+package domain
+
+// CacheStats reports how the service response cache is doing. Coalesced
+// counts callers that waited on an identical in-flight request instead of
+// issuing their own.
+type CacheStats struct {
+	Entries    int                         `json:"entries"`
+	MaxEntries int                         `json:"maxEntries"`
+	Hits       uint64                      `json:"hits"`
+	Misses     uint64                      `json:"misses"`
+	Coalesced  uint64                      `json:"coalesced"`
+	Evictions  uint64                      `json:"evictions"`
+	Methods    map[string]CacheMethodStats `json:"methods"`
+}
+
+type CacheMethodStats struct {
+	TTLSeconds float64 `json:"ttlSeconds"`
+	Entries    int     `json:"entries"`
+	Hits       uint64  `json:"hits"`
+	Misses     uint64  `json:"misses"`
+	Coalesced  uint64  `json:"coalesced"`
+}
@@ -2,6 +2,13 @@ package domain
 
 import "time"
 
+// SystemNamespaces are left out of every pod listing and ranking.
+var SystemNamespaces = map[string]bool{
+	"kube-system":     true,
+	"kube-public":     true,
+	"kube-node-lease": true,
+}
+
 type ContainerInfo struct {
 	ID       string            `json:"id"`
 	Name     string            `json:"name"`
@@ -34,6 +41,21 @@ type ContainerLogs struct {
 	Lines       []string `json:"lines"`
 }
 
+type LogPattern struct {
+	Pattern   string     `json:"pattern"`
+	Count     int        `json:"count"`
+	Percent   float64    `json:"percent"`
+	FirstSeen *time.Time `json:"firstSeen,omitempty"`
+	LastSeen  *time.Time `json:"lastSeen,omitempty"`
+	Example   string     `json:"example"`
+}
+
+type ContainerLogPatterns struct {
+	ContainerID string       `json:"containerId"`
+	TotalLines  int          `json:"totalLines"`
+	Patterns    []LogPattern `json:"patterns"`
+}
+
 type AppDependency struct {
 	SourceApp       string `json:"sourceApp"`
 	SourceNamespace string `json:"sourceNamespace"`
@@ -49,4 +71,35 @@ type NodeInfo struct {
 	MemoryGB    float64 `json:"memoryGB"`
 	OSImage     string  `json:"osImage"`
 	ProxmoxHost string  `json:"proxmoxHost,omitempty"`
+	// Pressure lists the node's active pressure conditions, e.g. DiskPressure.
+	Pressure []string `json:"pressure,omitempty"`
+}
+
+// PodDiagnostics is the state the rule-based analyzer reasons about for one
+// pod. Unschedulable holds the scheduler's message while the pod can't be
+// placed; ProbeFailures counts recent failed liveness and readiness probes.
+type PodDiagnostics struct {
+	Namespace     string                 `json:"namespace"`
+	Pod           string                 `json:"pod"`
+	App           string                 `json:"app"`
+	Node          string                 `json:"node,omitempty"`
+	Phase         string                 `json:"phase"`
+	Ready         bool                   `json:"ready"`
+	Created       time.Time              `json:"created"`
+	Restarts      int32                  `json:"restarts"`
+	Containers    []ContainerDiagnostics `json:"containers"`
+	Unschedulable string                 `json:"unschedulable,omitempty"`
+	ProbeFailures int32                  `json:"probeFailures,omitempty"`
+	ProbeMessage  string                 `json:"probeMessage,omitempty"`
+}
+
+// ContainerDiagnostics carries why a container is waiting and how it last
+// exited.
+type ContainerDiagnostics struct {
+	Name           string `json:"name"`
+	Restarts       int32  `json:"restarts"`
+	Waiting        string `json:"waiting,omitempty"`
+	WaitingMessage string `json:"waitingMessage,omitempty"`
+	LastTerminated string `json:"lastTerminated,omitempty"`
+	LastExitCode   int32  `json:"lastExitCode,omitempty"`
 }
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrSnapshotNotFound = errors.New("no insight snapshot at or before the requested time")
+
+// InsightSnapshot identifies one side of an InsightDiff.
+type InsightSnapshot struct {
+	CollectedAt *time.Time `json:"collected_at"`
+	Status      string     `json:"status"`
+	Summary     string     `json:"summary"`
+	Source      string     `json:"source,omitempty"`
+}
+
+// InsightDiff is what changed between two insights. Anomalies are matched by
+// fingerprint, so a change in severity shows as one resolved and one added.
+// From is nil when To is the first insight recorded.
+type InsightDiff struct {
+	From                   *InsightSnapshot   `json:"from"`
+	To                     InsightSnapshot    `json:"to"`
+	StatusChanged          bool               `json:"status_changed"`
+	Added                  []OverwatchAnomaly `json:"added"`
+	Resolved               []OverwatchAnomaly `json:"resolved"`
+	Persisting             []OverwatchAnomaly `json:"persisting"`
+	RecommendationsAdded   []string           `json:"recommendations_added"`
+	RecommendationsRemoved []string           `json:"recommendations_removed"`
+}
+
+func snapshotOf(i OverwatchInsight) InsightSnapshot {
+	return InsightSnapshot{CollectedAt: i.CollectedAt, Status: i.Status, Summary: i.Summary, Source: i.Source}
+}
+
+// DiffInsights compares from, which may be nil, with to. Persisting anomalies
+// are taken from to so they carry its acks.
+func DiffInsights(from *OverwatchInsight, to OverwatchInsight) InsightDiff {
+	diff := InsightDiff{
+		To:                     snapshotOf(to),
+		Added:                  []OverwatchAnomaly{},
+		Resolved:               []OverwatchAnomaly{},
+		Persisting:             []OverwatchAnomaly{},
+		RecommendationsAdded:   []string{},
+		RecommendationsRemoved: []string{},
+	}
+	var before OverwatchInsight
+	if from != nil {
+		snap := snapshotOf(*from)
+		diff.From = &snap
+		diff.StatusChanged = from.Status != to.Status
+		before = *from
+	}
+
+	fingerprint := func(a OverwatchAnomaly) string {
+		if a.Fingerprint != "" {
+			return a.Fingerprint
+		}
+		return AnomalyFingerprint(a)
+	}
+	old := make(map[string]bool, len(before.Anomalies))
+	for _, a := range before.Anomalies {
+		old[fingerprint(a)] = true
+	}
+	current := make(map[string]bool, len(to.Anomalies))
+	for _, a := range to.Anomalies {
+		fp := fingerprint(a)
+		current[fp] = true
+		if old[fp] {
+			diff.Persisting = append(diff.Persisting, a)
+		} else {
+			diff.Added = append(diff.Added, a)
+		}
+	}
+	for _, a := range before.Anomalies {
+		if !current[fingerprint(a)] {
+			diff.Resolved = append(diff.Resolved, a)
+		}
+	}
+
+	oldRecs := make(map[string]bool, len(before.Recommendations))
+	for _, r := range before.Recommendations {
+		oldRecs[r] = true
+	}
+	newRecs := make(map[string]bool, len(to.Recommendations))
+	for _, r := range to.Recommendations {
+		newRecs[r] = true
+		if !oldRecs[r] {
+			diff.RecommendationsAdded = append(diff.RecommendationsAdded, r)
+		}
+	}
+	for _, r := range before.Recommendations {
+		if !newRecs[r] {
+			diff.RecommendationsRemoved = append(diff.RecommendationsRemoved, r)
+		}
+	}
+	return diff
+}
+
+// WithoutHidden returns a copy of the diff without hidden anomalies.
+func (d InsightDiff) WithoutHidden() InsightDiff {
+	filter := func(anomalies []OverwatchAnomaly) []OverwatchAnomaly {
+		visible := make([]OverwatchAnomaly, 0, len(anomalies))
+		for _, a := range anomalies {
+			if !a.Hidden {
+				visible = append(visible, a)
+			}
+		}
+		return visible
+	}
+	d.Added, d.Resolved, d.Persisting = filter(d.Added), filter(d.Resolved), filter(d.Persisting)
+	return d
+}
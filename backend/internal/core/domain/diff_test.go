@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiffInsights(t *testing.T) {
+	cpu := OverwatchAnomaly{Type: "cpu_spike", Affected: "media/jellyfin", Severity: "medium"}
+	cpuHigh := OverwatchAnomaly{Type: "cpu_spike", Affected: "media/jellyfin", Severity: "high"}
+	disk := OverwatchAnomaly{Type: "disk_full", Affected: "node-1", Severity: "high"}
+	// An upstream fingerprint wins over the derived one.
+	restarts := OverwatchAnomaly{Type: "high_restarts", Affected: "web/api", Severity: "medium", Fingerprint: "restarts"}
+	restartsAgain := OverwatchAnomaly{Type: "high_restarts", Affected: "web/api", Severity: "high", Fingerprint: "restarts"}
+
+	tests := []struct {
+		name          string
+		from          *OverwatchInsight
+		to            OverwatchInsight
+		wantChanged   bool
+		added         []string
+		resolved      []string
+		persisting    []string
+		recsAdded     []string
+		recsRemoved   []string
+		wantFromIsNil bool
+	}{
+		{
+			name:          "first insight",
+			to:            OverwatchInsight{Status: "warning", Anomalies: []OverwatchAnomaly{cpu}, Recommendations: []string{"a"}},
+			added:         []string{"medium cpu_spike"},
+			recsAdded:     []string{"a"},
+			wantFromIsNil: true,
+		},
+		{
+			name:        "added, resolved and persisting",
+			from:        &OverwatchInsight{Status: "warning", Anomalies: []OverwatchAnomaly{cpu, restarts}, Recommendations: []string{"a", "b"}},
+			to:          OverwatchInsight{Status: "critical", Anomalies: []OverwatchAnomaly{restartsAgain, disk}, Recommendations: []string{"b", "c"}},
+			wantChanged: true,
+			added:       []string{"high disk_full"},
+			resolved:    []string{"medium cpu_spike"},
+			persisting:  []string{"high high_restarts"},
+			recsAdded:   []string{"c"},
+			recsRemoved: []string{"a"},
+		},
+		{
+			name:     "severity change is resolved and added",
+			from:     &OverwatchInsight{Status: "warning", Anomalies: []OverwatchAnomaly{cpu}},
+			to:       OverwatchInsight{Status: "warning", Anomalies: []OverwatchAnomaly{cpuHigh}},
+			added:    []string{"high cpu_spike"},
+			resolved: []string{"medium cpu_spike"},
+		},
+		{
+			name: "no change",
+			from: &OverwatchInsight{Status: "healthy"},
+			to:   OverwatchInsight{Status: "healthy"},
+		},
+	}
+	describe := func(anomalies []OverwatchAnomaly) []string {
+		out := make([]string, len(anomalies))
+		for i, a := range anomalies {
+			out[i] = a.Severity + " " + a.Type
+		}
+		return out
+	}
+	orEmpty := func(s []string) []string {
+		if s == nil {
+			return []string{}
+		}
+		return s
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := DiffInsights(tt.from, tt.to)
+			if (d.From == nil) != tt.wantFromIsNil {
+				t.Errorf("From = %v, want nil: %v", d.From, tt.wantFromIsNil)
+			}
+			if d.StatusChanged != tt.wantChanged {
+				t.Errorf("StatusChanged = %v, want %v", d.StatusChanged, tt.wantChanged)
+			}
+			for _, c := range []struct {
+				field     string
+				got, want []string
+			}{
+				{"added", describe(d.Added), tt.added},
+				{"resolved", describe(d.Resolved), tt.resolved},
+				{"persisting", describe(d.Persisting), tt.persisting},
+				{"recommendations added", d.RecommendationsAdded, tt.recsAdded},
+				{"recommendations removed", d.RecommendationsRemoved, tt.recsRemoved},
+			} {
+				if c.got == nil {
+					t.Errorf("%s is nil, want an empty list", c.field)
+				}
+				if !slices.Equal(c.got, orEmpty(c.want)) {
+					t.Errorf("%s = %v, want %v", c.field, c.got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestInsightDiffWithoutHidden(t *testing.T) {
+	shown := OverwatchAnomaly{Type: "cpu_spike"}
+	hidden := OverwatchAnomaly{Type: "disk_full", Hidden: true}
+	d := InsightDiff{
+		Added:      []OverwatchAnomaly{shown, hidden},
+		Resolved:   []OverwatchAnomaly{hidden},
+		Persisting: []OverwatchAnomaly{hidden, shown},
+	}
+
+	got := d.WithoutHidden()
+	if len(got.Added) != 1 || len(got.Resolved) != 0 || len(got.Persisting) != 1 {
+		t.Errorf("WithoutHidden() = %+v, want only the shown anomalies", got)
+	}
+	if got.Resolved == nil {
+		t.Error("Resolved is nil, want an empty list")
+	}
+	if len(d.Added) != 2 {
+		t.Error("WithoutHidden modified the original diff")
+	}
+}
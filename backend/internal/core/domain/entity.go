@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	EntityCluster = "cluster"
+	EntityNode    = "node"
+	EntityApp     = "app"
+	EntityPod     = "pod"
+)
+
+var ErrEntityNotFound = errors.New("entity not found")
+
+// Entity IDs are "<kind>:<name>": "node:worker-1", "app:media/jellyfin",
+// "pod:media/jellyfin-7d9c-x2k4", and "cluster" for the cluster as a whole.
+func NodeEntityID(name string) string          { return EntityNode + ":" + name }
+func AppEntityID(namespace, app string) string { return EntityApp + ":" + namespace + "/" + app }
+func PodEntityID(namespace, pod string) string { return EntityPod + ":" + namespace + "/" + pod }
+
+// ParseEntityID splits an entity ID into kind and name; ok is false for
+// malformed IDs.
+func ParseEntityID(id string) (kind, name string, ok bool) {
+	if id == EntityCluster {
+		return EntityCluster, "", true
+	}
+	kind, name, found := strings.Cut(id, ":")
+	if !found || name == "" {
+		return "", "", false
+	}
+	switch kind {
+	case EntityNode:
+		return kind, name, !strings.Contains(name, "/")
+	case EntityApp, EntityPod:
+		ns, n, found := strings.Cut(name, "/")
+		return kind, name, found && ns != "" && n != "" && !strings.Contains(n, "/")
+	}
+	return "", "", false
+}
+
+// EntityInsights gathers everything known about one entity. Items reached
+// through a related entity (the pod's app, or an upstream or downstream app)
+// name it in Via.
+type EntityInsights struct {
+	ID          string             `json:"id"`
+	Kind        string             `json:"kind"`
+	Related     []string           `json:"related"`
+	Upstream    []string           `json:"upstream"`
+	Downstream  []string           `json:"downstream"`
+	Anomalies   []EntityAnomaly    `json:"anomalies"`
+	PodInsights []EntityPodInsight `json:"podInsights"`
+	Alerts      []EntityAlert      `json:"alerts"`
+	Errors      []string           `json:"errors,omitempty"`
+}
+
+type EntityAnomaly struct {
+	OverwatchAnomaly
+	CollectedAt *time.Time `json:"collected_at,omitempty"`
+	Source      string     `json:"source,omitempty"`
+	Via         string     `json:"via,omitempty"`
+}
+
+type EntityPodInsight struct {
+	PodInsight
+	Via string `json:"via,omitempty"`
+}
+
+type EntityAlert struct {
+	Alert
+	Via string `json:"via,omitempty"`
+}
@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+const (
+	CapacityKindFilesystem = "filesystem"
+	CapacityKindPVC        = "pvc"
+	CapacityKindMemory     = "memory"
+)
+
+// CapacityRange holds usage percent series used for forecasting: filesystems
+// labelled node and mountpoint, PVCs labelled namespace and
+// persistentvolumeclaim, and node memory labelled node.
+type CapacityRange struct {
+	Filesystems []Series     `json:"filesystems"`
+	PVCs        []Series     `json:"pvcs"`
+	Memory      []Series     `json:"memory"`
+	Warnings    []string     `json:"warnings,omitempty"`
+	Errors      []QueryError `json:"errors,omitempty"`
+}
+
+// ForecastQuery asks which resources will cross Threshold percent within
+// Horizon, extrapolating the trend over Range.
+type ForecastQuery struct {
+	Range     TimeRange
+	Horizon   time.Duration
+	Threshold float64
+}
+
+// CapacityForecast is the linear trend of one resource's usage percent. FullAt
+// and TimeToFullSeconds are nil when usage is flat or shrinking.
+type CapacityForecast struct {
+	Kind              string            `json:"kind"`
+	Name              string            `json:"name"`
+	Labels            map[string]string `json:"labels"`
+	Current           float64           `json:"current"`
+	GrowthPerDay      float64           `json:"growthPerDay"`
+	R2                float64           `json:"r2"`
+	FullAt            *time.Time        `json:"fullAt,omitempty"`
+	TimeToFullSeconds *int64            `json:"timeToFullSeconds,omitempty"`
+	AtRisk            bool              `json:"atRisk"`
+}
+
+type CapacityReport struct {
+	HorizonSeconds int64              `json:"horizonSeconds"`
+	Threshold      float64            `json:"threshold"`
+	Forecasts      []CapacityForecast `json:"forecasts"`
+	Warnings       []string           `json:"warnings,omitempty"`
+	Errors         []QueryError       `json:"errors,omitempty"`
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// HistoryQuery selects insights collected in [From, To], newest first. Zero
+// times leave that end open. Cursor continues a previous page: it is the
+// NextCursor of that page and is opaque to callers.
+type HistoryQuery struct {
+	From   time.Time
+	To     time.Time
+	Limit  int
+	Cursor string
+}
+
+type HistoryPage struct {
+	Items      []OverwatchInsight
+	NextCursor string
+}
+
+// HistoryCursor encodes the position after an insight collected at t.
+func HistoryCursor(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// Upper is the newest collection time the query admits: To, narrowed to just
+// before the cursor's position when one is set. Zero means unbounded.
+func (q HistoryQuery) Upper() (time.Time, error) {
+	if q.Cursor == "" {
+		return q.To, nil
+	}
+	nanos, err := strconv.ParseInt(q.Cursor, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid cursor %q", q.Cursor)
+	}
+	before := time.Unix(0, nanos-1)
+	if q.To.IsZero() || before.Before(q.To) {
+		return before, nil
+	}
+	return q.To, nil
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistoryQueryUpper(t *testing.T) {
+	to := time.Unix(1_700_000_000, 0)
+	at := func(d time.Duration) string { return HistoryCursor(to.Add(d)) }
+
+	tests := []struct {
+		name    string
+		q       HistoryQuery
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "unbounded", q: HistoryQuery{}},
+		{name: "to only", q: HistoryQuery{To: to}, want: to},
+		{name: "cursor only", q: HistoryQuery{Cursor: at(0)}, want: to.Add(-time.Nanosecond)},
+		{name: "cursor before to", q: HistoryQuery{To: to, Cursor: at(-time.Minute)}, want: to.Add(-time.Minute - time.Nanosecond)},
+		{name: "cursor after to", q: HistoryQuery{To: to, Cursor: at(time.Minute)}, want: to},
+		{name: "cursor at to", q: HistoryQuery{To: to, Cursor: at(0)}, want: to.Add(-time.Nanosecond)},
+		{name: "bad cursor", q: HistoryQuery{Cursor: "yesterday"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.q.Upper()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Upper() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Upper() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
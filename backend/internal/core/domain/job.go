@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+
+	JobKindPodInsight     = "pod-insight"
+	JobKindClusterInsight = "cluster-insight"
+)
+
+var ErrJobNotFound = errors.New("job not found")
+
+// Job is a pod analysis or cluster insight refresh running in the
+// background. Result holds a *PodInsight or *OverwatchInsight depending on
+// Kind once Status is done.
+type Job struct {
+	ID         string     `json:"id"`
+	Kind       string     `json:"kind"`
+	Namespace  string     `json:"namespace,omitempty"`
+	App        string     `json:"app,omitempty"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Result     any        `json:"result,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
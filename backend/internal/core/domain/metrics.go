@@ -1,16 +1,107 @@
 package domain
 
+import "time"
+
 type MetricPoint struct {
-	Time  string  `json:"time"`
-	Value float64 `json:"value"`
+	Time      string  `json:"time"`
+	Timestamp int64   `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+// NewMetricPoint stamps a value with both an RFC3339 time and epoch seconds.
+func NewMetricPoint(t time.Time, value float64) MetricPoint {
+	return MetricPoint{
+		Time:      t.UTC().Format(time.RFC3339),
+		Timestamp: t.Unix(),
+		Value:     value,
+	}
 }
 
 type MetricsRange struct {
-	CPU       []MetricPoint `json:"cpu"`
-	Memory    []MetricPoint `json:"memory"`
-	Disk      []MetricPoint `json:"disk"`
-	NetworkRx []MetricPoint `json:"networkRx"`
-	NetworkTx []MetricPoint `json:"networkTx"`
-	DiskRead  []MetricPoint `json:"diskRead"`
-	DiskWrite []MetricPoint `json:"diskWrite"`
+	CPU          []MetricPoint `json:"cpu"`
+	Memory       []MetricPoint `json:"memory"`
+	Disk         []MetricPoint `json:"disk"`
+	NetworkRx    []MetricPoint `json:"networkRx"`
+	NetworkTx    []MetricPoint `json:"networkTx"`
+	DiskRead     []MetricPoint `json:"diskRead"`
+	DiskWrite    []MetricPoint `json:"diskWrite"`
+	CPUThrottled []MetricPoint `json:"cpuThrottled"`
+	OOMEvents    []MetricPoint `json:"oomEvents"`
+	Warnings     []string      `json:"warnings,omitempty"`
+	Errors       []QueryError  `json:"errors,omitempty"`
+}
+
+// QueryError reports a metric that could not be fetched while the rest of the
+// response was still served.
+type QueryError struct {
+	Metric string `json:"metric"`
+	Error  string `json:"error"`
+}
+
+type LogMetricsRange struct {
+	Lines  []MetricPoint `json:"lines"`
+	Errors []MetricPoint `json:"errors"`
+}
+
+type Series struct {
+	Labels map[string]string `json:"labels"`
+	Points []MetricPoint     `json:"points"`
+}
+
+type MetricsBreakdown struct {
+	Filesystems []Series     `json:"filesystems"`
+	NetworkRx   []Series     `json:"networkRx"`
+	NetworkTx   []Series     `json:"networkTx"`
+	DiskRead    []Series     `json:"diskRead"`
+	DiskWrite   []Series     `json:"diskWrite"`
+	Warnings    []string     `json:"warnings,omitempty"`
+	Errors      []QueryError `json:"errors,omitempty"`
+}
+
+const (
+	MetricScopeHost = "host"
+	MetricScopeNode = "node"
+	MetricScopePod  = "pod"
+)
+
+type MetricDefinition struct {
+	Name        string `json:"name"`
+	Scope       string `json:"scope"`
+	Unit        string `json:"unit"`
+	Description string `json:"description,omitempty"`
+	Query       string `json:"query"`
+}
+
+type CatalogMetricRange struct {
+	MetricDefinition
+	Series   []Series `json:"series"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// NodeMetricsSnapshot holds the latest value of each node-level metric. A nil
+// field means Prometheus returned no data for it.
+type NodeMetricsSnapshot struct {
+	CPU           *float64 `json:"cpu"`
+	Memory        *float64 `json:"memory"`
+	Disk          *float64 `json:"disk"`
+	Load1         *float64 `json:"load1"`
+	Uptime        *float64 `json:"uptime"`
+	TotalMemory   *float64 `json:"totalMemory"`
+	DiskReadRate  *float64 `json:"diskReadRate"`
+	DiskWriteRate *float64 `json:"diskWriteRate"`
+	NetworkRxRate *float64 `json:"networkRxRate"`
+	NetworkTxRate *float64 `json:"networkTxRate"`
+}
+
+// NodeMetrics is a node's inventory entry alongside its current metrics.
+type NodeMetrics struct {
+	NodeInfo
+	NodeMetricsSnapshot
+}
+
+// ClusterMetrics is the cluster-wide snapshot plus one snapshot per node.
+type ClusterMetrics struct {
+	NodeMetricsSnapshot
+	Nodes  []NodeMetrics `json:"nodes"`
+	Errors []QueryError  `json:"errors,omitempty"`
 }
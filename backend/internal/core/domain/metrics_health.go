@@ -0,0 +1,71 @@
+package domain
+
+import "time"
+
+// MetricsHealth explains empty charts: whether Prometheus answers, which scrape
+// targets are down and which rules fail to evaluate.
+type MetricsHealth struct {
+	Up      bool               `json:"up"`
+	Targets TargetsHealth      `json:"targets"`
+	Rules   RulesHealth        `json:"rules"`
+	Runtime *PrometheusRuntime `json:"runtime,omitempty"`
+	TSDB    *TSDBStats         `json:"tsdb,omitempty"`
+	Errors  []QueryError       `json:"errors,omitempty"`
+}
+
+// TargetsHealth counts active scrape targets by health and lists the ones that
+// are not up.
+type TargetsHealth struct {
+	Total     int            `json:"total"`
+	Up        int            `json:"up"`
+	Down      int            `json:"down"`
+	Unknown   int            `json:"unknown"`
+	Unhealthy []TargetHealth `json:"unhealthy"`
+}
+
+type TargetHealth struct {
+	Job                string    `json:"job"`
+	Instance           string    `json:"instance"`
+	ScrapePool         string    `json:"scrapePool"`
+	ScrapeURL          string    `json:"scrapeUrl"`
+	Health             string    `json:"health"`
+	LastError          string    `json:"lastError,omitempty"`
+	LastScrape         time.Time `json:"lastScrape"`
+	LastScrapeDuration float64   `json:"lastScrapeDuration"`
+}
+
+// RulesHealth counts rule groups and rules and lists every rule whose last
+// evaluation failed.
+type RulesHealth struct {
+	Groups        int          `json:"groups"`
+	Rules         int          `json:"rules"`
+	FailingGroups int          `json:"failingGroups"`
+	Failing       []RuleHealth `json:"failing"`
+}
+
+type RuleHealth struct {
+	Group          string    `json:"group"`
+	File           string    `json:"file"`
+	Name           string    `json:"name"`
+	Type           string    `json:"type"`
+	Health         string    `json:"health"`
+	LastError      string    `json:"lastError,omitempty"`
+	LastEvaluation time.Time `json:"lastEvaluation"`
+}
+
+type PrometheusRuntime struct {
+	StartTime           time.Time `json:"startTime"`
+	ReloadConfigSuccess bool      `json:"reloadConfigSuccess"`
+	LastConfigTime      time.Time `json:"lastConfigTime"`
+	StorageRetention    string    `json:"storageRetention"`
+	CorruptionCount     int64     `json:"corruptionCount"`
+	GoroutineCount      int       `json:"goroutineCount"`
+}
+
+// TSDBStats describes the head block. MinTime and MaxTime are unix milliseconds.
+type TSDBStats struct {
+	NumSeries  int64 `json:"numSeries"`
+	ChunkCount int64 `json:"chunkCount"`
+	MinTime    int64 `json:"minTime"`
+	MaxTime    int64 `json:"maxTime"`
+}
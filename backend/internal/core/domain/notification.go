@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+const (
+	NotifyAnomaly      = "anomaly"
+	NotifyNodeNotReady = "node_not_ready"
+	NotifyCrashLoop    = "crash_loop"
+	NotifyAlert        = "alert"
+
+	SeverityLow    = "low"
+	SeverityMedium = "medium"
+	SeverityHigh   = "high"
+)
+
+// Notification is one event worth telling someone about. Key identifies the
+// underlying condition so repeats can be suppressed.
+type Notification struct {
+	Kind     string            `json:"kind"`
+	Key      string            `json:"key"`
+	Severity string            `json:"severity"`
+	Title    string            `json:"title"`
+	Message  string            `json:"message"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Time     time.Time         `json:"time"`
+}
+
+// NotificationRule routes matching notifications to named sinks. Empty Kinds
+// matches every kind; Match requires each label to have the given value.
+// At most MaxPerHour notifications are sent per rule (zero is unlimited) and
+// a key already sent within DedupWindow is skipped.
+type NotificationRule struct {
+	Name        string
+	Kinds       []string
+	MinSeverity string
+	Match       map[string]string
+	Sinks       []string
+	MaxPerHour  int
+	DedupWindow time.Duration
+}
+
+// SeverityRank orders severities for MinSeverity comparisons.
+func SeverityRank(severity string) int {
+	switch severity {
+	case SeverityHigh:
+		return 3
+	case SeverityMedium:
+		return 2
+	case SeverityLow:
+		return 1
+	}
+	return 0
+}
@@ -1,14 +1,83 @@
 package domain
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
 
 type OverwatchAnomaly struct {
 	Severity    string `json:"severity"`
 	Type        string `json:"type"`
 	Description string `json:"description"`
 	Affected    string `json:"affected"`
+	// Fingerprint identifies the same anomaly across insights.
+	Fingerprint string      `json:"fingerprint,omitempty"`
+	Ack         *AnomalyAck `json:"ack,omitempty"`
+	// Hidden is set while the anomaly is snoozed or muted.
+	Hidden bool `json:"hidden,omitempty"`
+	// Entities are the entity IDs Affected was resolved to.
+	Entities []string `json:"entities,omitempty"`
 }
 
+// AnomalyFingerprint derives a stable ID from what an anomaly is about,
+// ignoring its wording, which changes between refreshes.
+func AnomalyFingerprint(a OverwatchAnomaly) string {
+	sum := sha256.Sum256([]byte(a.Type + "\x00" + a.Affected + "\x00" + a.Severity))
+	return hex.EncodeToString(sum[:8])
+}
+
+const (
+	// AckAcknowledged marks an anomaly as seen; it stays visible.
+	AckAcknowledged = "acknowledged"
+	// AckSnoozed hides an anomaly until Until.
+	AckSnoozed = "snoozed"
+	// AckMuted hides an anomaly until the ack is removed.
+	AckMuted = "muted"
+)
+
+var ErrAckNotFound = errors.New("acknowledgement not found")
+
+// AnomalyAck records how an operator handled an anomaly fingerprint. An ack
+// with Until set lapses at that time.
+type AnomalyAck struct {
+	Fingerprint string     `json:"fingerprint"`
+	State       string     `json:"state"`
+	Until       *time.Time `json:"until,omitempty"`
+	Note        string     `json:"note,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Active reports whether the ack still applies at now.
+func (a AnomalyAck) Active(now time.Time) bool {
+	return a.Until == nil || now.Before(*a.Until)
+}
+
+// Hides reports whether the ack keeps its anomaly out of default listings.
+func (a AnomalyAck) Hides(now time.Time) bool {
+	return a.Active(now) && (a.State == AckSnoozed || a.State == AckMuted)
+}
+
+// WithoutHidden returns a copy of the insight without hidden anomalies.
+func (i OverwatchInsight) WithoutHidden() OverwatchInsight {
+	visible := make([]OverwatchAnomaly, 0, len(i.Anomalies))
+	for _, a := range i.Anomalies {
+		if !a.Hidden {
+			visible = append(visible, a)
+		}
+	}
+	i.HiddenAnomalies = len(i.Anomalies) - len(visible)
+	i.Anomalies = visible
+	return i
+}
+
+const (
+	InsightSourceOverwatch = "overwatch"
+	InsightSourceLocal     = "local"
+	InsightSourceRules     = "rules"
+)
+
 type OverwatchInsight struct {
 	ID              *int               `json:"id,omitempty"`
 	CollectedAt     *time.Time         `json:"collected_at"`
@@ -16,6 +85,11 @@ type OverwatchInsight struct {
 	Summary         string             `json:"summary"`
 	Anomalies       []OverwatchAnomaly `json:"anomalies"`
 	Recommendations []string           `json:"recommendations"`
+	// Source is InsightSourceLocal when produced by the built-in detector and
+	// InsightSourceRules when produced by the rule-based analyzer.
+	Source string `json:"source,omitempty"`
+	// HiddenAnomalies counts snoozed and muted anomalies left out.
+	HiddenAnomalies int `json:"hidden_anomalies,omitempty"`
 }
 
 type PodInsight struct {
@@ -26,4 +100,5 @@ type PodInsight struct {
 	Diagnosis   string    `json:"diagnosis"`
 	RootCause   string    `json:"root_cause"`
 	Suggestions []string  `json:"suggestions"`
+	Entity      string    `json:"entity,omitempty"`
 }
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// TimeRange is a resolved query window: absolute start and end plus the
+// resolution between returned points.
+type TimeRange struct {
+	Start time.Time     `json:"start"`
+	End   time.Time     `json:"end"`
+	Step  time.Duration `json:"step"`
+}
+
+// RateWindow is the lookback used for rate() style functions. It spans at
+// least two steps so that no samples fall between consecutive points, and at
+// least a minute so short ranges still cover several scrapes.
+func (r TimeRange) RateWindow() time.Duration {
+	w := 2 * r.Step
+	if w < time.Minute {
+		w = time.Minute
+	}
+	return w
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+const (
+	TopByCPU      = "cpu"
+	TopByMemory   = "memory"
+	TopByNetwork  = "network"
+	TopByDiskIO   = "diskio"
+	TopByRestarts = "restarts"
+
+	TopKindPod  = "pod"
+	TopKindApp  = "app"
+	TopKindNode = "node"
+
+	TopSourcePrometheus    = "prometheus"
+	TopSourceMetricsServer = "metrics-server"
+)
+
+// TopQuery selects what to rank: By is one of the TopBy* constants, Kind one of
+// the TopKind* constants. Window is the lookback for rates and restart counts.
+// A Limit of zero asks for every entry.
+type TopQuery struct {
+	By     string
+	Kind   string
+	Limit  int
+	Window time.Duration
+}
+
+type TopEntry struct {
+	Name      string  `json:"name"`
+	Namespace string  `json:"namespace,omitempty"`
+	Value     float64 `json:"value"`
+}
+
+// TopConsumers is a ranking of pods, apps or nodes, highest value first.
+type TopConsumers struct {
+	By            string       `json:"by"`
+	Kind          string       `json:"kind"`
+	Unit          string       `json:"unit"`
+	WindowSeconds int64        `json:"windowSeconds"`
+	Source        string       `json:"source"`
+	Entries       []TopEntry   `json:"entries"`
+	Warnings      []string     `json:"warnings,omitempty"`
+	Errors        []QueryError `json:"errors,omitempty"`
+}
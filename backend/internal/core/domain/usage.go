@@ -0,0 +1,60 @@
+package domain
+
+// PodResources is a pod's resource requests and limits summed over its
+// containers. CPU is in cores and memory in bytes; a zero limit means at least
+// one container has no limit set.
+type PodResources struct {
+	Namespace     string  `json:"namespace"`
+	Pod           string  `json:"pod"`
+	App           string  `json:"app"`
+	Node          string  `json:"node"`
+	CPURequest    float64 `json:"cpuRequest"`
+	CPULimit      float64 `json:"cpuLimit"`
+	MemoryRequest float64 `json:"memoryRequest"`
+	MemoryLimit   float64 `json:"memoryLimit"`
+}
+
+// PodUsage is a pod's current CPU (cores) and memory (bytes) usage.
+type PodUsage struct {
+	Namespace string  `json:"namespace"`
+	Pod       string  `json:"pod"`
+	CPU       float64 `json:"cpu"`
+	Memory    float64 `json:"memory"`
+}
+
+type PodUsageReport struct {
+	Pods     []PodUsage   `json:"pods"`
+	Warnings []string     `json:"warnings,omitempty"`
+	Errors   []QueryError `json:"errors,omitempty"`
+}
+
+// PodUsageRange holds one CPU and one memory series per pod, labelled with
+// namespace and pod.
+type PodUsageRange struct {
+	CPU      []Series     `json:"cpu"`
+	Memory   []Series     `json:"memory"`
+	Warnings []string     `json:"warnings,omitempty"`
+	Errors   []QueryError `json:"errors,omitempty"`
+}
+
+// ResourceUsage is the usage of a group of pods (a namespace, or an app within
+// a namespace) next to the sum of their requests and limits.
+type ResourceUsage struct {
+	Namespace     string        `json:"namespace"`
+	App           string        `json:"app,omitempty"`
+	Pods          int           `json:"pods"`
+	CPU           float64       `json:"cpu"`
+	Memory        float64       `json:"memory"`
+	CPURequest    float64       `json:"cpuRequest"`
+	CPULimit      float64       `json:"cpuLimit"`
+	MemoryRequest float64       `json:"memoryRequest"`
+	MemoryLimit   float64       `json:"memoryLimit"`
+	CPUSeries     []MetricPoint `json:"cpuSeries,omitempty"`
+	MemorySeries  []MetricPoint `json:"memorySeries,omitempty"`
+}
+
+type ResourceUsageReport struct {
+	Groups   []ResourceUsage `json:"groups"`
+	Warnings []string        `json:"warnings,omitempty"`
+	Errors   []QueryError    `json:"errors,omitempty"`
+}
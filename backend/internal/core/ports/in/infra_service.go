@@ -2,6 +2,7 @@ package in
 
 import (
 	"context"
+	"time"
 
 	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
 )
@@ -11,15 +12,49 @@ type InfraService interface {
 	ListContainers(ctx context.Context) ([]domain.ContainerInfo, error)
 	GetContainerStats(ctx context.Context, id string) (*domain.ContainerStats, error)
 	GetContainerLogs(ctx context.Context, id, tail string) (*domain.ContainerLogs, error)
+	GetContainerLogPatterns(ctx context.Context, id, tail string) (*domain.ContainerLogPatterns, error)
 	ListNetworks(ctx context.Context) ([]domain.NetworkInfo, error)
 	GetSystemInfo(ctx context.Context) (*domain.SystemInfo, error)
-	GetNodeMetrics(ctx context.Context) (map[string]interface{}, error)
-	GetMetricsRange(ctx context.Context, duration, containerName string) (*domain.MetricsRange, error)
-	GetNodeMetricsRange(ctx context.Context, node, duration string) (*domain.MetricsRange, error)
+	GetNodeMetrics(ctx context.Context) (*domain.ClusterMetrics, error)
+	GetMetricsRange(ctx context.Context, rng domain.TimeRange, containerName string) (*domain.MetricsRange, error)
+	GetNodeMetricsRange(ctx context.Context, node string, rng domain.TimeRange) (*domain.MetricsRange, error)
+	GetMetricsBreakdown(ctx context.Context, node string, rng domain.TimeRange) (*domain.MetricsBreakdown, error)
+	GetNamespaceUsage(ctx context.Context, rng *domain.TimeRange) (*domain.ResourceUsageReport, error)
+	GetAppUsage(ctx context.Context, rng *domain.TimeRange) (*domain.ResourceUsageReport, error)
+	GetTopConsumers(ctx context.Context, q domain.TopQuery) (*domain.TopConsumers, error)
+	ForecastCapacity(ctx context.Context, q domain.ForecastQuery) (*domain.CapacityReport, error)
+	GetMetricsHealth(ctx context.Context) (*domain.MetricsHealth, error)
+	ListMetricDefinitions(ctx context.Context) ([]domain.MetricDefinition, error)
+	GetCatalogMetricRange(ctx context.Context, scope, name, node, containerName string, rng domain.TimeRange) (*domain.CatalogMetricRange, error)
+	GetLogMetricsRange(ctx context.Context, rng domain.TimeRange, namespace, app string) (*domain.LogMetricsRange, error)
+	ListAlertGroups(ctx context.Context) ([]domain.AlertGroup, error)
+	ListSilences(ctx context.Context) ([]domain.Silence, error)
+	CreateSilence(ctx context.Context, silence domain.Silence) (string, error)
+	ExpireSilence(ctx context.Context, id string) error
 	ListDependencies(ctx context.Context) ([]domain.AppDependency, error)
 	ListNodes(ctx context.Context) ([]domain.NodeInfo, error)
+	DetectAnomalies(ctx context.Context, rng domain.TimeRange) (*domain.OverwatchInsight, error)
 	GetOverwatchInsights(ctx context.Context) (*domain.OverwatchInsight, error)
 	GetPodInsights(ctx context.Context, namespace, app string) (*domain.PodInsight, error)
 	GetAllPodInsights(ctx context.Context) ([]domain.PodInsight, error)
-	GetOverwatchHistory(ctx context.Context) ([]domain.OverwatchInsight, error)
+	GetOverwatchHistory(ctx context.Context, q domain.HistoryQuery) (*domain.HistoryPage, error)
+	DiffOverwatchInsights(ctx context.Context, from, to time.Time) (*domain.InsightDiff, error)
+	AcknowledgeAnomaly(ctx context.Context, ack domain.AnomalyAck) (*domain.AnomalyAck, error)
+	ListAnomalyAcks(ctx context.Context) ([]domain.AnomalyAck, error)
+	DeleteAnomalyAck(ctx context.Context, fingerprint string) error
+	GetEntityInsights(ctx context.Context, id string) (*domain.EntityInsights, error)
+	StartPodAnalysis(ctx context.Context, namespace, app string) (*domain.Job, error)
+	StartClusterRefresh(ctx context.Context) (*domain.Job, error)
+	GetJob(ctx context.Context, id string) (*domain.Job, error)
+}
+
+// JobNotifier is implemented by services that run analyses in the
+// background; fn is called with every job that finishes.
+type JobNotifier interface {
+	OnJobDone(fn func(domain.Job))
+}
+
+// CacheStatsReader is implemented by services that cache responses.
+type CacheStatsReader interface {
+	CacheStats() domain.CacheStats
 }
@@ -0,0 +1,14 @@
+package out
+
+import (
+	"context"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+type AlertRepository interface {
+	ListAlertGroups(ctx context.Context) ([]domain.AlertGroup, error)
+	ListSilences(ctx context.Context) ([]domain.Silence, error)
+	CreateSilence(ctx context.Context, silence domain.Silence) (string, error)
+	ExpireSilence(ctx context.Context, id string) error
+}
@@ -15,4 +15,7 @@ type ClusterRepository interface {
 	GetSystemInfo(ctx context.Context) (*domain.SystemInfo, error)
 	ListDependencies(ctx context.Context) ([]domain.AppDependency, error)
 	ListNodes(ctx context.Context) ([]domain.NodeInfo, error)
+	GetTopConsumers(ctx context.Context, q domain.TopQuery) (*domain.TopConsumers, error)
+	ListPodResources(ctx context.Context) ([]domain.PodResources, error)
+	ListPodDiagnostics(ctx context.Context) ([]domain.PodDiagnostics, error)
 }
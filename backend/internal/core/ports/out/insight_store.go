@@ -0,0 +1,24 @@
+package out
+
+import (
+	"context"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+// InsightStore keeps the insights the agent has seen so history survives
+// Overwatch redeploys and outages, along with anomaly acknowledgements.
+// Saving an insight already stored (same collection time, or same app and
+// analysis time) leaves the stored one alone, so re-saving what Overwatch
+// returns costs no write; acks are keyed by fingerprint and replaced.
+type InsightStore interface {
+	SaveInsight(ctx context.Context, insight domain.OverwatchInsight) error
+	SavePodInsight(ctx context.Context, insight domain.PodInsight) error
+	ListInsights(ctx context.Context, q domain.HistoryQuery) (*domain.HistoryPage, error)
+	// LatestPodInsights returns the most recent insight of every app.
+	LatestPodInsights(ctx context.Context) ([]domain.PodInsight, error)
+	SaveAck(ctx context.Context, ack domain.AnomalyAck) error
+	ListAcks(ctx context.Context) ([]domain.AnomalyAck, error)
+	// DeleteAck returns domain.ErrAckNotFound for unknown fingerprints.
+	DeleteAck(ctx context.Context, fingerprint string) error
+}
@@ -0,0 +1,13 @@
+package out
+
+import (
+	"context"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+// LogMetricsRepository reads log rates. Empty namespace and app select every
+// stream in the cluster.
+type LogMetricsRepository interface {
+	GetLogMetricsRange(ctx context.Context, rng domain.TimeRange, namespace, app string) (*domain.LogMetricsRange, error)
+}
@@ -7,7 +7,15 @@ import (
 )
 
 type MetricsRepository interface {
-	GetNodeMetrics(ctx context.Context) (map[string]interface{}, error)
-	GetMetricsRange(ctx context.Context, duration, containerName string) (*domain.MetricsRange, error)
-	GetNodeMetricsRange(ctx context.Context, node, duration string) (*domain.MetricsRange, error)
+	GetNodeMetrics(ctx context.Context) (*domain.ClusterMetrics, error)
+	GetMetricsRange(ctx context.Context, rng domain.TimeRange, containerName string) (*domain.MetricsRange, error)
+	GetNodeMetricsRange(ctx context.Context, node string, rng domain.TimeRange) (*domain.MetricsRange, error)
+	GetPodUsage(ctx context.Context) (*domain.PodUsageReport, error)
+	GetPodUsageRange(ctx context.Context, rng domain.TimeRange) (*domain.PodUsageRange, error)
+	GetTopConsumers(ctx context.Context, q domain.TopQuery) (*domain.TopConsumers, error)
+	GetMetricsBreakdown(ctx context.Context, node string, rng domain.TimeRange) (*domain.MetricsBreakdown, error)
+	GetCapacityRange(ctx context.Context, rng domain.TimeRange) (*domain.CapacityRange, error)
+	GetMetricsHealth(ctx context.Context) (*domain.MetricsHealth, error)
+	ListMetricDefinitions(ctx context.Context) ([]domain.MetricDefinition, error)
+	GetCatalogMetricRange(ctx context.Context, scope, name, node, containerName string, rng domain.TimeRange) (*domain.CatalogMetricRange, error)
 }
@@ -0,0 +1,13 @@
+package out
+
+import (
+	"context"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+// Notifier delivers notifications to one destination, e.g. a chat webhook
+// or a mailbox.
+type Notifier interface {
+	Notify(ctx context.Context, n domain.Notification) error
+}
@@ -10,5 +10,8 @@ type OverwatchRepository interface {
 	GetInsights(ctx context.Context) (*domain.OverwatchInsight, error)
 	GetPodInsights(ctx context.Context, namespace, app string) (*domain.PodInsight, error)
 	GetAllPodInsights(ctx context.Context) ([]domain.PodInsight, error)
-	GetHistory(ctx context.Context) ([]domain.OverwatchInsight, error)
+	// GetHistory returns insights for the query's time range, up to one more
+	// than its limit. Overwatch pages by limit only, so callers filter by
+	// cursor and cut the page themselves.
+	GetHistory(ctx context.Context, q domain.HistoryQuery) ([]domain.OverwatchInsight, error)
 }
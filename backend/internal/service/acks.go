@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+// memoryAcks holds acknowledgements when no insight store is configured;
+// they are lost on restart.
+type memoryAcks struct {
+	mu   sync.Mutex
+	acks map[string]domain.AnomalyAck
+}
+
+func newMemoryAcks() *memoryAcks {
+	return &memoryAcks{acks: make(map[string]domain.AnomalyAck)}
+}
+
+func (s *infraService) saveAck(ctx context.Context, ack domain.AnomalyAck) error {
+	if s.store != nil {
+		return s.store.SaveAck(ctx, ack)
+	}
+	s.memAcks.mu.Lock()
+	defer s.memAcks.mu.Unlock()
+	s.memAcks.acks[ack.Fingerprint] = ack
+	return nil
+}
+
+func (s *infraService) listAcks(ctx context.Context) ([]domain.AnomalyAck, error) {
+	if s.store != nil {
+		return s.store.ListAcks(ctx)
+	}
+	s.memAcks.mu.Lock()
+	defer s.memAcks.mu.Unlock()
+	acks := make([]domain.AnomalyAck, 0, len(s.memAcks.acks))
+	for _, ack := range s.memAcks.acks {
+		acks = append(acks, ack)
+	}
+	return acks, nil
+}
+
+func (s *infraService) AcknowledgeAnomaly(ctx context.Context, ack domain.AnomalyAck) (*domain.AnomalyAck, error) {
+	ack.CreatedAt = time.Now().UTC()
+	if err := s.saveAck(ctx, ack); err != nil {
+		return nil, err
+	}
+	return &ack, nil
+}
+
+// ListAnomalyAcks returns the acks still in effect.
+func (s *infraService) ListAnomalyAcks(ctx context.Context) ([]domain.AnomalyAck, error) {
+	acks, err := s.listAcks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	active := make([]domain.AnomalyAck, 0, len(acks))
+	for _, ack := range acks {
+		if ack.Active(now) {
+			active = append(active, ack)
+		}
+	}
+	return active, nil
+}
+
+func (s *infraService) DeleteAnomalyAck(ctx context.Context, fingerprint string) error {
+	if s.store != nil {
+		return s.store.DeleteAck(ctx, fingerprint)
+	}
+	s.memAcks.mu.Lock()
+	defer s.memAcks.mu.Unlock()
+	if _, ok := s.memAcks.acks[fingerprint]; !ok {
+		return domain.ErrAckNotFound
+	}
+	delete(s.memAcks.acks, fingerprint)
+	return nil
+}
+
+// annotate fingerprints every anomaly, resolves it to entities and attaches
+// the acks in effect. Acks that can't be loaded are logged and skipped rather
+// than failing the read.
+func (s *infraService) annotate(ctx context.Context, insights ...*domain.OverwatchInsight) {
+	s.resolveEntities(ctx, insights)
+
+	byFingerprint := make(map[string]domain.AnomalyAck)
+	if acks, err := s.ListAnomalyAcks(ctx); err != nil {
+		log.Printf("[service] load anomaly acks: %v", err)
+	} else {
+		for _, ack := range acks {
+			byFingerprint[ack.Fingerprint] = ack
+		}
+	}
+
+	now := time.Now()
+	for _, insight := range insights {
+		if insight == nil {
+			continue
+		}
+		for i := range insight.Anomalies {
+			a := &insight.Anomalies[i]
+			a.Fingerprint = domain.AnomalyFingerprint(*a)
+			a.Ack, a.Hidden = nil, false
+			if ack, ok := byFingerprint[a.Fingerprint]; ok {
+				a.Ack = &ack
+				a.Hidden = ack.Hides(now)
+			}
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+const (
+	// anomalyLookback and anomalyStep give the seasonal check two full days
+	// while keeping the rolling windows a few hours long.
+	anomalyLookback = 48 * time.Hour
+	anomalyStep     = 10 * time.Minute
+
+	// anomalyWindow is the number of preceding points the rolling z-score uses.
+	anomalyWindow = 30
+	// anomalyRecent is how many of the latest points are checked; older
+	// deviations are history, not current anomalies.
+	anomalyRecent = 3
+	// ewmaAlpha weights the newest point in the EWMA baseline.
+	ewmaAlpha = 0.3
+	// seasonalPeriod is the daily cycle the seasonal check compares against.
+	seasonalPeriod = 24 * time.Hour
+
+	// Scores at or above these become low, medium and high anomalies.
+	anomalyScoreLow    = 3.0
+	anomalyScoreMedium = 4.5
+	anomalyScoreHigh   = 6.0
+)
+
+// anomalySeries is one metric for one affected entity. floor is the smallest
+// standard deviation assumed, so that near-flat series don't flag tiny moves.
+type anomalySeries struct {
+	affected string
+	metric   string
+	unit     string
+	floor    float64
+	points   []domain.MetricPoint
+}
+
+type anomalyScore struct {
+	method   string
+	score    float64
+	value    float64
+	baseline float64
+}
+
+// DetectAnomalies runs rolling z-score, EWMA and seasonal (day-over-day) checks
+// on cluster, node and app CPU and memory, pod restarts and the cluster log
+// error rate over rng. Only increases are flagged. The result has the same
+// shape as an Overwatch insight so the two can be compared.
+func (s *infraService) DetectAnomalies(ctx context.Context, rng domain.TimeRange) (*domain.OverwatchInsight, error) {
+	series, err := s.collectAnomalySeries(ctx, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	anomalies := []domain.OverwatchAnomaly{}
+	for _, sr := range series {
+		if a, ok := detectSeriesAnomaly(sr, rng.Step); ok {
+			anomalies = append(anomalies, a)
+		}
+	}
+	severityRank := map[string]int{"high": 3, "medium": 2, "low": 1}
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		return severityRank[anomalies[i].Severity] > severityRank[anomalies[j].Severity]
+	})
+
+	now := time.Now().UTC()
+	insight := &domain.OverwatchInsight{
+		CollectedAt:     &now,
+		Status:          "healthy",
+		Summary:         fmt.Sprintf("Local detector checked %d series; no anomalies found.", len(series)),
+		Anomalies:       anomalies,
+		Recommendations: []string{},
+		Source:          domain.InsightSourceLocal,
+	}
+	if len(anomalies) > 0 {
+		insight.Status = "warning"
+		if anomalies[0].Severity == "high" {
+			insight.Status = "critical"
+		}
+		insight.Summary = fmt.Sprintf("Local detector found %d anomalies across %d series.", len(anomalies), len(series))
+	}
+	s.annotate(ctx, insight)
+	return insight, nil
+}
+
+// collectAnomalySeries gathers every series the detector checks. Sources that
+// fail are skipped; it only errors when none could be read.
+func (s *infraService) collectAnomalySeries(ctx context.Context, rng domain.TimeRange) ([]anomalySeries, error) {
+	var series []anomalySeries
+	var failures []string
+	fail := func(source string, err error) {
+		log.Printf("[anomaly] %s: %v", source, err)
+		failures = append(failures, source+": "+err.Error())
+	}
+
+	if host, err := s.metrics.GetMetricsRange(ctx, rng, ""); err == nil {
+		series = append(series,
+			anomalySeries{affected: "cluster", metric: "cpu", unit: "%", floor: 2, points: host.CPU},
+			anomalySeries{affected: "cluster", metric: "memory", unit: "%", floor: 2, points: host.Memory},
+		)
+	} else {
+		fail("cluster metrics", err)
+	}
+
+	if nodes, err := s.cluster.ListNodes(ctx); err == nil {
+		for _, node := range nodes {
+			data, err := s.metrics.GetNodeMetricsRange(ctx, node.Name, rng)
+			if err != nil {
+				fail("node "+node.Name, err)
+				continue
+			}
+			series = append(series,
+				anomalySeries{affected: node.Name, metric: "cpu", unit: "%", floor: 2, points: data.CPU},
+				anomalySeries{affected: node.Name, metric: "memory", unit: "%", floor: 2, points: data.Memory},
+			)
+		}
+	} else {
+		fail("nodes", err)
+	}
+
+	pods, err := s.cluster.ListPodResources(ctx)
+	if err != nil {
+		fail("pods", err)
+	}
+	podGroups := make(map[string]usageKey, len(pods))
+	for _, p := range pods {
+		podGroups[p.Namespace+"/"+p.Pod] = usageKey{namespace: p.Namespace, app: p.App}
+	}
+	appName := func(key usageKey) string { return key.namespace + "/" + key.app }
+
+	if len(pods) > 0 {
+		if usage, err := s.metrics.GetPodUsageRange(ctx, rng); err == nil {
+			for key, points := range sumSeriesByGroup(usage.CPU, podGroups) {
+				series = append(series, anomalySeries{affected: appName(key), metric: "cpu", unit: " cores", floor: 0.02, points: points})
+			}
+			for key, points := range sumSeriesByGroup(usage.Memory, podGroups) {
+				series = append(series, anomalySeries{affected: appName(key), metric: "memory", unit: " bytes", floor: 16 << 20, points: points})
+			}
+		} else {
+			fail("pod usage", err)
+		}
+
+		if restarts, err := s.metrics.GetCatalogMetricRange(ctx, domain.MetricScopeHost, "restartsByPod", "", "", rng); err == nil {
+			for key, points := range sumSeriesByGroup(restarts.Series, podGroups) {
+				series = append(series, anomalySeries{affected: appName(key), metric: "restarts", floor: 0.5, points: points})
+			}
+		} else {
+			fail("restarts", err)
+		}
+	}
+
+	if logs, err := s.logs.GetLogMetricsRange(ctx, rng, "", ""); err == nil {
+		series = append(series, anomalySeries{affected: "cluster", metric: "error_rate", unit: "%", floor: 1, points: errorRatio(logs)})
+	} else {
+		fail("log error rate", err)
+	}
+
+	if len(series) == 0 && len(failures) > 0 {
+		return nil, fmt.Errorf("anomaly detection: no series available: %s", strings.Join(failures, "; "))
+	}
+	sort.Slice(series, func(i, j int) bool {
+		if series[i].affected != series[j].affected {
+			return series[i].affected < series[j].affected
+		}
+		return series[i].metric < series[j].metric
+	})
+	return series, nil
+}
+
+// errorRatio turns log line and error rates into the percentage of lines that
+// are errors, aligned on timestamp.
+func errorRatio(logs *domain.LogMetricsRange) []domain.MetricPoint {
+	errs := make(map[int64]float64, len(logs.Errors))
+	for _, p := range logs.Errors {
+		errs[p.Timestamp] = p.Value
+	}
+	points := make([]domain.MetricPoint, 0, len(logs.Lines))
+	for _, p := range logs.Lines {
+		ratio := 0.0
+		if p.Value > 0 {
+			ratio = errs[p.Timestamp] / p.Value * 100
+		}
+		points = append(points, domain.NewMetricPoint(time.Unix(p.Timestamp, 0), ratio))
+	}
+	return points
+}
+
+// detectSeriesAnomaly scores the latest points of a series with every method
+// that has enough history and reports the strongest result.
+func detectSeriesAnomaly(s anomalySeries, step time.Duration) (domain.OverwatchAnomaly, bool) {
+	if len(s.points) <= anomalyWindow {
+		return domain.OverwatchAnomaly{}, false
+	}
+
+	var scores []anomalyScore
+	if sc, ok := zScoreCheck(s.points, s.floor); ok {
+		scores = append(scores, sc)
+	}
+	if sc, ok := ewmaCheck(s.points, s.floor); ok {
+		scores = append(scores, sc)
+	}
+	if sc, ok := seasonalCheck(s.points, step, s.floor); ok {
+		scores = append(scores, sc)
+	}
+
+	var flagged []anomalyScore
+	for _, sc := range scores {
+		if sc.score >= anomalyScoreLow {
+			flagged = append(flagged, sc)
+		}
+	}
+	if len(flagged) == 0 {
+		return domain.OverwatchAnomaly{}, false
+	}
+	sort.Slice(flagged, func(i, j int) bool { return flagged[i].score > flagged[j].score })
+	top := flagged[0]
+
+	severity := "low"
+	switch {
+	case top.score >= anomalyScoreHigh:
+		severity = "high"
+	case top.score >= anomalyScoreMedium:
+		severity = "medium"
+	}
+	methods := make([]string, len(flagged))
+	for i, sc := range flagged {
+		methods[i] = sc.method
+	}
+
+	return domain.OverwatchAnomaly{
+		Severity: severity,
+		Type:     s.metric + "_spike",
+		Description: fmt.Sprintf("%s %s is %s%s against a baseline of %s%s (score %.1f; %s)",
+			s.affected, strings.ReplaceAll(s.metric, "_", " "),
+			formatAnomalyValue(top.value), s.unit, formatAnomalyValue(top.baseline), s.unit,
+			top.score, strings.Join(methods, ", ")),
+		Affected: s.affected,
+	}, true
+}
+
+// zScoreCheck compares each recent point with the mean and deviation of the
+// anomalyWindow points before it.
+func zScoreCheck(points []domain.MetricPoint, floor float64) (anomalyScore, bool) {
+	best := anomalyScore{method: "z-score"}
+	found := false
+	for i := max(len(points)-anomalyRecent, anomalyWindow); i < len(points); i++ {
+		mean, std := meanStd(points[i-anomalyWindow : i])
+		score := (points[i].Value - mean) / stdFloor(std, mean, floor)
+		if !found || score > best.score {
+			best.score, best.value, best.baseline = score, points[i].Value, mean
+			found = true
+		}
+	}
+	return best, found
+}
+
+// ewmaCheck tracks an exponentially weighted mean and variance and scores each
+// recent point against the baseline as it stood just before that point.
+func ewmaCheck(points []domain.MetricPoint, floor float64) (anomalyScore, bool) {
+	best := anomalyScore{method: "ewma"}
+	found := false
+	mean := points[0].Value
+	variance := 0.0
+	for i := 1; i < len(points); i++ {
+		x := points[i].Value
+		if i >= len(points)-anomalyRecent && i >= anomalyWindow {
+			score := (x - mean) / stdFloor(math.Sqrt(variance), mean, floor)
+			if !found || score > best.score {
+				best.score, best.value, best.baseline = score, x, mean
+				found = true
+			}
+		}
+		diff := x - mean
+		mean += ewmaAlpha * diff
+		variance = (1 - ewmaAlpha) * (variance + ewmaAlpha*diff*diff)
+	}
+	return best, found
+}
+
+// seasonalCheck compares each point with the one a day earlier and scores the
+// latest day-over-day differences against the history of those differences.
+// It needs at least two days of data.
+func seasonalCheck(points []domain.MetricPoint, step time.Duration, floor float64) (anomalyScore, bool) {
+	if step <= 0 {
+		return anomalyScore{}, false
+	}
+	lag := int(seasonalPeriod / step)
+	if lag < 1 || len(points) < 2*lag {
+		return anomalyScore{}, false
+	}
+
+	byTime := make(map[int64]float64, len(points))
+	for _, p := range points {
+		byTime[p.Timestamp] = p.Value
+	}
+	period := int64(seasonalPeriod / time.Second)
+
+	type residual struct {
+		value, previous float64
+		diff            float64
+	}
+	var residuals []residual
+	for _, p := range points {
+		prev, ok := byTime[p.Timestamp-period]
+		if !ok {
+			continue
+		}
+		residuals = append(residuals, residual{value: p.Value, previous: prev, diff: p.Value - prev})
+	}
+	if len(residuals) <= anomalyWindow {
+		return anomalyScore{}, false
+	}
+
+	history := make([]domain.MetricPoint, 0, len(residuals)-anomalyRecent)
+	for _, r := range residuals[:len(residuals)-anomalyRecent] {
+		history = append(history, domain.MetricPoint{Value: r.diff})
+	}
+	mean, std := meanStd(history)
+
+	best := anomalyScore{method: "seasonal"}
+	found := false
+	for _, r := range residuals[len(residuals)-anomalyRecent:] {
+		score := (r.diff - mean) / stdFloor(std, r.previous, floor)
+		if !found || score > best.score {
+			best.score, best.value, best.baseline = score, r.value, r.previous
+			found = true
+		}
+	}
+	return best, found
+}
+
+func meanStd(points []domain.MetricPoint) (mean, std float64) {
+	for _, p := range points {
+		mean += p.Value
+	}
+	mean /= float64(len(points))
+	for _, p := range points {
+		std += (p.Value - mean) * (p.Value - mean)
+	}
+	return mean, math.Sqrt(std / float64(len(points)))
+}
+
+// stdFloor keeps the deviation used for scoring at least 5% of the baseline
+// and at least the series' absolute floor.
+func stdFloor(std, baseline, floor float64) float64 {
+	return math.Max(std, math.Max(0.05*math.Abs(baseline), floor))
+}
+
+func formatAnomalyValue(v float64) string {
+	switch {
+	case math.Abs(v) >= 1<<20:
+		return fmt.Sprintf("%.0f", v)
+	case math.Abs(v) >= 10:
+		return fmt.Sprintf("%.1f", v)
+	default:
+		return fmt.Sprintf("%.2f", v)
+	}
+}
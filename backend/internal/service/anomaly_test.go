@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+	portout "github.com/isaacwallace123/portfolio-infra/internal/core/ports/out"
+)
+
+var anomalyBase = time.Unix(1_700_000_000, 0)
+
+// flatSeries returns n points of value spaced step apart, with the last
+// point replaced by last.
+func flatSeries(n int, value, last float64, step time.Duration) []domain.MetricPoint {
+	points := make([]domain.MetricPoint, n)
+	for i := range points {
+		points[i] = domain.NewMetricPoint(anomalyBase.Add(time.Duration(i)*step), value)
+	}
+	points[n-1].Value = last
+	return points
+}
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestMeanStdAndFloor(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		wantMean float64
+		wantStd  float64
+	}{
+		{name: "constant", values: []float64{4, 4, 4}, wantMean: 4, wantStd: 0},
+		{name: "spread", values: []float64{2, 4, 4, 4, 5, 5, 7, 9}, wantMean: 5, wantStd: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points := make([]domain.MetricPoint, len(tt.values))
+			for i, v := range tt.values {
+				points[i].Value = v
+			}
+			mean, std := meanStd(points)
+			if !approx(mean, tt.wantMean) || !approx(std, tt.wantStd) {
+				t.Errorf("meanStd = %v, %v; want %v, %v", mean, std, tt.wantMean, tt.wantStd)
+			}
+		})
+	}
+
+	floors := []struct {
+		std, baseline, floor, want float64
+	}{
+		{std: 3, baseline: 10, floor: 0, want: 3},
+		{std: 0, baseline: 10, floor: 0, want: 0.5},
+		{std: 0, baseline: -10, floor: 0, want: 0.5},
+		{std: 0.1, baseline: 10, floor: 2, want: 2},
+	}
+	for _, f := range floors {
+		if got := stdFloor(f.std, f.baseline, f.floor); !approx(got, f.want) {
+			t.Errorf("stdFloor(%v, %v, %v) = %v, want %v", f.std, f.baseline, f.floor, got, f.want)
+		}
+	}
+}
+
+func TestPointChecks(t *testing.T) {
+	type check func([]domain.MetricPoint, float64) (anomalyScore, bool)
+	checks := map[string]check{"z-score": zScoreCheck, "ewma": ewmaCheck}
+
+	tests := []struct {
+		name      string
+		points    []domain.MetricPoint
+		floor     float64
+		wantScore float64
+		wantValue float64
+		wantBase  float64
+	}{
+		// A flat baseline has no deviation, so 5% of it (0.5) is used.
+		{name: "spike over flat baseline", points: flatSeries(40, 10, 20, time.Minute), wantScore: 20, wantValue: 20, wantBase: 10},
+		{name: "floor caps the score", points: flatSeries(40, 10, 20, time.Minute), floor: 5, wantScore: 2, wantValue: 20, wantBase: 10},
+		{name: "no change", points: flatSeries(40, 10, 10, time.Minute), wantScore: 0, wantValue: 10, wantBase: 10},
+	}
+	for name, fn := range checks {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				sc, ok := fn(tt.points, tt.floor)
+				if !ok {
+					t.Fatal("no score")
+				}
+				if sc.method != name {
+					t.Errorf("method = %q, want %q", sc.method, name)
+				}
+				if !approx(sc.score, tt.wantScore) || sc.value != tt.wantValue || !approx(sc.baseline, tt.wantBase) {
+					t.Errorf("score = %+v, want score %v value %v baseline %v", sc, tt.wantScore, tt.wantValue, tt.wantBase)
+				}
+			})
+		}
+	}
+}
+
+func TestSeasonalCheck(t *testing.T) {
+	// Three days of hourly points that repeat daily, with the last point 50
+	// above the same hour the day before.
+	daily := make([]domain.MetricPoint, 72)
+	for i := range daily {
+		daily[i] = domain.NewMetricPoint(anomalyBase.Add(time.Duration(i)*time.Hour), float64(10+i%24))
+	}
+	spiked := append([]domain.MetricPoint(nil), daily...)
+	spiked[71].Value += 50
+
+	tests := []struct {
+		name      string
+		points    []domain.MetricPoint
+		step      time.Duration
+		wantOK    bool
+		wantScore float64
+	}{
+		{name: "repeating day", points: daily, step: time.Hour, wantOK: true, wantScore: 0},
+		// previous = 33, so the deviation floor is 5% of it.
+		{name: "day-over-day jump", points: spiked, step: time.Hour, wantOK: true, wantScore: 50 / (0.05 * 33)},
+		{name: "under two days", points: daily[:47], step: time.Hour},
+		{name: "no step", points: daily, step: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, ok := seasonalCheck(tt.points, tt.step, 0)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !approx(sc.score, tt.wantScore) {
+				t.Errorf("score = %v, want %v", sc.score, tt.wantScore)
+			}
+		})
+	}
+}
+
+func TestDetectSeriesAnomalySeverity(t *testing.T) {
+	tests := []struct {
+		name         string
+		points       []domain.MetricPoint
+		wantSeverity string // empty means not flagged
+	}{
+		// With a floor of 1, the score is the rise above the baseline of 10.
+		{name: "below threshold", points: flatSeries(40, 10, 12, anomalyStep)},
+		{name: "drop is ignored", points: flatSeries(40, 10, 2, anomalyStep)},
+		{name: "low", points: flatSeries(40, 10, 13.5, anomalyStep), wantSeverity: "low"},
+		{name: "medium", points: flatSeries(40, 10, 15, anomalyStep), wantSeverity: "medium"},
+		{name: "high", points: flatSeries(40, 10, 17, anomalyStep), wantSeverity: "high"},
+		{name: "too short", points: flatSeries(anomalyWindow, 10, 100, anomalyStep)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := anomalySeries{affected: "media/jellyfin", metric: "cpu", unit: "%", floor: 1, points: tt.points}
+			a, ok := detectSeriesAnomaly(s, anomalyStep)
+			if ok != (tt.wantSeverity != "") {
+				t.Fatalf("flagged = %v, want %v (%+v)", ok, tt.wantSeverity != "", a)
+			}
+			if !ok {
+				return
+			}
+			if a.Severity != tt.wantSeverity || a.Type != "cpu_spike" || a.Affected != "media/jellyfin" {
+				t.Errorf("anomaly = %+v, want %s cpu_spike on media/jellyfin", a, tt.wantSeverity)
+			}
+		})
+	}
+}
+
+// emptyCluster has no nodes, pods or dependencies.
+type emptyCluster struct{ portout.ClusterRepository }
+
+func (emptyCluster) ListContainers(context.Context) ([]domain.ContainerInfo, error) { return nil, nil }
+func (emptyCluster) ListNodes(context.Context) ([]domain.NodeInfo, error)           { return nil, nil }
+func (emptyCluster) ListPodResources(context.Context) ([]domain.PodResources, error) {
+	return nil, nil
+}
+func (emptyCluster) ListDependencies(context.Context) ([]domain.AppDependency, error) {
+	return nil, nil
+}
+
+// noMetrics fails every metrics query, leaving the log series alone.
+type noMetrics struct{ portout.MetricsRepository }
+
+func (noMetrics) GetMetricsRange(context.Context, domain.TimeRange, string) (*domain.MetricsRange, error) {
+	return nil, errors.New("prometheus not configured")
+}
+
+// fakeLogs answers cluster-wide queries with 100 lines/sec, 1% of them errors
+// until the last point, where errors jump to 30%.
+type fakeLogs struct{}
+
+func (fakeLogs) GetLogMetricsRange(_ context.Context, _ domain.TimeRange, namespace, app string) (*domain.LogMetricsRange, error) {
+	if namespace != "" || app != "" {
+		return nil, fmt.Errorf("want a cluster-wide query, got %q/%q", namespace, app)
+	}
+	return &domain.LogMetricsRange{
+		Lines:  flatSeries(40, 100, 100, anomalyStep),
+		Errors: flatSeries(40, 1, 30, anomalyStep),
+	}, nil
+}
+
+func TestDetectAnomaliesReportsErrorRate(t *testing.T) {
+	s := NewInfraService(emptyCluster{}, noMetrics{}, fakeLogs{}, nil, nil, nil)
+	rng := domain.TimeRange{Start: anomalyBase, End: anomalyBase.Add(40 * anomalyStep), Step: anomalyStep}
+
+	insight, err := s.DetectAnomalies(context.Background(), rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(insight.Anomalies) != 1 {
+		t.Fatalf("anomalies = %+v, want one", insight.Anomalies)
+	}
+	a := insight.Anomalies[0]
+	if a.Type != "error_rate_spike" || a.Affected != "cluster" || a.Severity != "high" {
+		t.Errorf("anomaly = %+v, want a high error_rate_spike on cluster", a)
+	}
+	if insight.Status != "critical" {
+		t.Errorf("status = %q, want critical", insight.Status)
+	}
+}
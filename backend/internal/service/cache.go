@@ -0,0 +1,531 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+	portin "github.com/isaacwallace123/portfolio-infra/internal/core/ports/in"
+)
+
+const (
+	defaultCacheMaxEntries = 1024
+	// cacheFetchTimeout bounds a shared fetch when the caller set no deadline.
+	cacheFetchTimeout = time.Minute
+)
+
+// DefaultCacheTTLs is how long each InfraService method's results are reused.
+// Methods missing from the map, and those that change state, are not cached.
+func DefaultCacheTTLs() map[string]time.Duration {
+	return map[string]time.Duration{
+		"ListContainers":          15 * time.Second,
+		"GetContainerStats":       10 * time.Second,
+		"GetContainerLogs":        5 * time.Second,
+		"GetContainerLogPatterns": 30 * time.Second,
+		"ListNetworks":            time.Minute,
+		"GetSystemInfo":           time.Minute,
+		"GetNodeMetrics":          10 * time.Second,
+		"GetMetricsRange":         30 * time.Second,
+		"GetNodeMetricsRange":     30 * time.Second,
+		"GetMetricsBreakdown":     30 * time.Second,
+		"GetNamespaceUsage":       30 * time.Second,
+		"GetAppUsage":             30 * time.Second,
+		"GetTopConsumers":         15 * time.Second,
+		"ForecastCapacity":        5 * time.Minute,
+		"GetMetricsHealth":        15 * time.Second,
+		"ListMetricDefinitions":   5 * time.Minute,
+		"GetCatalogMetricRange":   30 * time.Second,
+		"GetLogMetricsRange":      30 * time.Second,
+		"ListAlertGroups":         15 * time.Second,
+		"ListSilences":            15 * time.Second,
+		"ListDependencies":        time.Minute,
+		"ListNodes":               time.Minute,
+		"DetectAnomalies":         2 * time.Minute,
+		"GetOverwatchInsights":    time.Minute,
+		"GetPodInsights":          time.Minute,
+		"GetAllPodInsights":       time.Minute,
+		"GetOverwatchHistory":     time.Minute,
+		"GetEntityInsights":       30 * time.Second,
+		"DiffOverwatchInsights":   time.Minute,
+	}
+}
+
+// ParseCacheTTLs overrides the defaults with a "Method=duration,..." spec,
+// e.g. "ListContainers=30s,GetSystemInfo=0" (zero disables caching).
+func ParseCacheTTLs(spec string) (map[string]time.Duration, error) {
+	ttls := DefaultCacheTTLs()
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		method, value, ok := strings.Cut(pair, "=")
+		method = strings.TrimSpace(method)
+		if !ok {
+			return nil, fmt.Errorf("%q is not Method=duration", pair)
+		}
+		if _, known := ttls[method]; !known {
+			return nil, fmt.Errorf("unknown or uncacheable method %q", method)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid TTL for %s: %q", method, value)
+		}
+		ttls[method] = d
+	}
+	return ttls, nil
+}
+
+type CacheConfig struct {
+	// MaxEntries bounds the number of cached results across all methods.
+	MaxEntries int
+	// TTLs per method name; nil uses DefaultCacheTTLs.
+	TTLs map[string]time.Duration
+}
+
+type cacheEntry struct {
+	method  string
+	value   any
+	expires time.Time
+}
+
+type cacheCounters struct {
+	hits, misses, coalesced uint64
+}
+
+// cachedInfraService decorates an InfraService with per-method TTL caching.
+// Concurrent identical calls share one backend request.
+type cachedInfraService struct {
+	next       portin.InfraService
+	ttls       map[string]time.Duration
+	maxEntries int
+	group      singleflight.Group
+
+	mu        sync.Mutex
+	entries   map[string]*cacheEntry
+	counters  map[string]*cacheCounters
+	evictions uint64
+}
+
+func NewCachedInfraService(next portin.InfraService, cfg CacheConfig) portin.InfraService {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = defaultCacheMaxEntries
+	}
+	if cfg.TTLs == nil {
+		cfg.TTLs = DefaultCacheTTLs()
+	}
+	c := &cachedInfraService{
+		next:       next,
+		ttls:       cfg.TTLs,
+		maxEntries: cfg.MaxEntries,
+		entries:    make(map[string]*cacheEntry),
+		counters:   make(map[string]*cacheCounters),
+	}
+	if n, ok := next.(portin.JobNotifier); ok {
+		n.OnJobDone(c.jobDone)
+	}
+	return c
+}
+
+// jobDone drops the entries a finished analysis makes stale. Jobs run on the
+// wrapped service, so their results never pass through the cache.
+func (c *cachedInfraService) jobDone(job domain.Job) {
+	if job.Status != domain.JobDone {
+		return
+	}
+	switch job.Kind {
+	case domain.JobKindPodInsight:
+		c.invalidate("GetPodInsights", "GetAllPodInsights", "GetEntityInsights")
+	case domain.JobKindClusterInsight:
+		c.invalidate("GetOverwatchInsights", "GetOverwatchHistory", "DiffOverwatchInsights", "GetEntityInsights")
+	}
+}
+
+// cached returns the cached result of method for key, or calls fetch once for
+// all concurrent callers and caches a successful result. Every caller gets its
+// own copy, so changing a result never changes what later callers see.
+func cached[T any](c *cachedInfraService, ctx context.Context, method, key string, fetch func(context.Context) (T, error)) (T, error) {
+	ttl := c.ttls[method]
+	if ttl <= 0 {
+		return fetch(ctx)
+	}
+	key = method + "|" + key
+
+	if v, ok := c.lookup(method, key); ok {
+		return deepCopy(v.(T)), nil
+	}
+
+	ran := false
+	ch := c.group.DoChan(key, func() (any, error) {
+		ran = true
+		fetchCtx, cancel := fetchContext(ctx)
+		defer cancel()
+		v, err := fetch(fetchCtx)
+		if err == nil {
+			c.store(method, key, v, ttl)
+		}
+		return v, err
+	})
+
+	var zero T
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case res := <-ch:
+		c.count(method, ran)
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		return deepCopy(res.Val.(T)), nil
+	}
+}
+
+// fetchContext is the context of a shared fetch. The fetch outlives any single
+// caller going away, but keeps the leader's deadline so it is not left running
+// indefinitely. Only the leader creates one, so no caller leaves a context
+// uncancelled.
+func fetchContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(context.WithoutCancel(ctx), deadline)
+	}
+	return context.WithTimeout(context.WithoutCancel(ctx), cacheFetchTimeout)
+}
+
+func (c *cachedInfraService) lookup(method, key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.countersFor(method).hits++
+	return e.value, true
+}
+
+func (c *cachedInfraService) store(method, key string, value any, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		now := time.Now()
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		// Still full: drop whatever would expire soonest.
+		for len(c.entries) >= c.maxEntries {
+			var victim string
+			var soonest time.Time
+			for k, e := range c.entries {
+				if victim == "" || e.expires.Before(soonest) {
+					victim, soonest = k, e.expires
+				}
+			}
+			delete(c.entries, victim)
+			c.evictions++
+		}
+	}
+	c.entries[key] = &cacheEntry{method: method, value: value, expires: time.Now().Add(ttl)}
+}
+
+func (c *cachedInfraService) count(method string, ran bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ran {
+		c.countersFor(method).misses++
+	} else {
+		c.countersFor(method).coalesced++
+	}
+}
+
+// countersFor must be called with c.mu held.
+func (c *cachedInfraService) countersFor(method string) *cacheCounters {
+	cnt, ok := c.counters[method]
+	if !ok {
+		cnt = &cacheCounters{}
+		c.counters[method] = cnt
+	}
+	return cnt
+}
+
+// invalidate drops every cached result of the given methods.
+func (c *cachedInfraService) invalidate(methods ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, e := range c.entries {
+		for _, m := range methods {
+			if e.method == m {
+				delete(c.entries, k)
+				break
+			}
+		}
+	}
+}
+
+func (c *cachedInfraService) CacheStats() domain.CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := domain.CacheStats{
+		MaxEntries: c.maxEntries,
+		Evictions:  c.evictions,
+		Methods:    make(map[string]domain.CacheMethodStats, len(c.ttls)),
+	}
+	now := time.Now()
+	perMethod := make(map[string]int)
+	for _, e := range c.entries {
+		if now.Before(e.expires) {
+			perMethod[e.method]++
+			stats.Entries++
+		}
+	}
+
+	methods := make([]string, 0, len(c.ttls))
+	for m, ttl := range c.ttls {
+		if ttl > 0 {
+			methods = append(methods, m)
+		}
+	}
+	sort.Strings(methods)
+	for _, m := range methods {
+		ms := domain.CacheMethodStats{TTLSeconds: c.ttls[m].Seconds(), Entries: perMethod[m]}
+		if cnt, ok := c.counters[m]; ok {
+			ms.Hits, ms.Misses, ms.Coalesced = cnt.hits, cnt.misses, cnt.coalesced
+		}
+		stats.Hits += ms.Hits
+		stats.Misses += ms.Misses
+		stats.Coalesced += ms.Coalesced
+		stats.Methods[m] = ms
+	}
+	return stats
+}
+
+// rangeKey identifies a time range by its length, step and end rounded down
+// to the step, so relative ranges ("last hour") requested a few seconds apart
+// share an entry.
+func rangeKey(rng domain.TimeRange) string {
+	step := max(rng.Step, time.Second)
+	return fmt.Sprintf("%d/%d/%d", rng.End.Truncate(step).Unix(), int64(rng.End.Sub(rng.Start).Seconds()), int64(step.Seconds()))
+}
+
+func (c *cachedInfraService) Health(ctx context.Context) error {
+	return c.next.Health(ctx)
+}
+
+func (c *cachedInfraService) ListContainers(ctx context.Context) ([]domain.ContainerInfo, error) {
+	return cached(c, ctx, "ListContainers", "", c.next.ListContainers)
+}
+
+func (c *cachedInfraService) GetContainerStats(ctx context.Context, id string) (*domain.ContainerStats, error) {
+	return cached(c, ctx, "GetContainerStats", id, func(ctx context.Context) (*domain.ContainerStats, error) {
+		return c.next.GetContainerStats(ctx, id)
+	})
+}
+
+func (c *cachedInfraService) GetContainerLogs(ctx context.Context, id, tail string) (*domain.ContainerLogs, error) {
+	return cached(c, ctx, "GetContainerLogs", id+"|"+tail, func(ctx context.Context) (*domain.ContainerLogs, error) {
+		return c.next.GetContainerLogs(ctx, id, tail)
+	})
+}
+
+func (c *cachedInfraService) GetContainerLogPatterns(ctx context.Context, id, tail string) (*domain.ContainerLogPatterns, error) {
+	return cached(c, ctx, "GetContainerLogPatterns", id+"|"+tail, func(ctx context.Context) (*domain.ContainerLogPatterns, error) {
+		return c.next.GetContainerLogPatterns(ctx, id, tail)
+	})
+}
+
+func (c *cachedInfraService) ListNetworks(ctx context.Context) ([]domain.NetworkInfo, error) {
+	return cached(c, ctx, "ListNetworks", "", c.next.ListNetworks)
+}
+
+func (c *cachedInfraService) GetSystemInfo(ctx context.Context) (*domain.SystemInfo, error) {
+	return cached(c, ctx, "GetSystemInfo", "", c.next.GetSystemInfo)
+}
+
+func (c *cachedInfraService) GetNodeMetrics(ctx context.Context) (*domain.ClusterMetrics, error) {
+	return cached(c, ctx, "GetNodeMetrics", "", c.next.GetNodeMetrics)
+}
+
+func (c *cachedInfraService) GetMetricsRange(ctx context.Context, rng domain.TimeRange, containerName string) (*domain.MetricsRange, error) {
+	return cached(c, ctx, "GetMetricsRange", rangeKey(rng)+"|"+containerName, func(ctx context.Context) (*domain.MetricsRange, error) {
+		return c.next.GetMetricsRange(ctx, rng, containerName)
+	})
+}
+
+func (c *cachedInfraService) GetNodeMetricsRange(ctx context.Context, node string, rng domain.TimeRange) (*domain.MetricsRange, error) {
+	return cached(c, ctx, "GetNodeMetricsRange", node+"|"+rangeKey(rng), func(ctx context.Context) (*domain.MetricsRange, error) {
+		return c.next.GetNodeMetricsRange(ctx, node, rng)
+	})
+}
+
+func (c *cachedInfraService) GetMetricsBreakdown(ctx context.Context, node string, rng domain.TimeRange) (*domain.MetricsBreakdown, error) {
+	return cached(c, ctx, "GetMetricsBreakdown", node+"|"+rangeKey(rng), func(ctx context.Context) (*domain.MetricsBreakdown, error) {
+		return c.next.GetMetricsBreakdown(ctx, node, rng)
+	})
+}
+
+func (c *cachedInfraService) GetNamespaceUsage(ctx context.Context, rng *domain.TimeRange) (*domain.ResourceUsageReport, error) {
+	return cached(c, ctx, "GetNamespaceUsage", optionalRangeKey(rng), func(ctx context.Context) (*domain.ResourceUsageReport, error) {
+		return c.next.GetNamespaceUsage(ctx, rng)
+	})
+}
+
+func (c *cachedInfraService) GetAppUsage(ctx context.Context, rng *domain.TimeRange) (*domain.ResourceUsageReport, error) {
+	return cached(c, ctx, "GetAppUsage", optionalRangeKey(rng), func(ctx context.Context) (*domain.ResourceUsageReport, error) {
+		return c.next.GetAppUsage(ctx, rng)
+	})
+}
+
+func optionalRangeKey(rng *domain.TimeRange) string {
+	if rng == nil {
+		return "now"
+	}
+	return rangeKey(*rng)
+}
+
+func (c *cachedInfraService) GetTopConsumers(ctx context.Context, q domain.TopQuery) (*domain.TopConsumers, error) {
+	key := fmt.Sprintf("%s|%s|%d|%s", q.By, q.Kind, q.Limit, q.Window)
+	return cached(c, ctx, "GetTopConsumers", key, func(ctx context.Context) (*domain.TopConsumers, error) {
+		return c.next.GetTopConsumers(ctx, q)
+	})
+}
+
+func (c *cachedInfraService) ForecastCapacity(ctx context.Context, q domain.ForecastQuery) (*domain.CapacityReport, error) {
+	key := fmt.Sprintf("%s|%s|%g", rangeKey(q.Range), q.Horizon, q.Threshold)
+	return cached(c, ctx, "ForecastCapacity", key, func(ctx context.Context) (*domain.CapacityReport, error) {
+		return c.next.ForecastCapacity(ctx, q)
+	})
+}
+
+func (c *cachedInfraService) GetMetricsHealth(ctx context.Context) (*domain.MetricsHealth, error) {
+	return cached(c, ctx, "GetMetricsHealth", "", c.next.GetMetricsHealth)
+}
+
+func (c *cachedInfraService) ListMetricDefinitions(ctx context.Context) ([]domain.MetricDefinition, error) {
+	return cached(c, ctx, "ListMetricDefinitions", "", c.next.ListMetricDefinitions)
+}
+
+func (c *cachedInfraService) GetCatalogMetricRange(ctx context.Context, scope, name, node, containerName string, rng domain.TimeRange) (*domain.CatalogMetricRange, error) {
+	key := strings.Join([]string{scope, name, node, containerName, rangeKey(rng)}, "|")
+	return cached(c, ctx, "GetCatalogMetricRange", key, func(ctx context.Context) (*domain.CatalogMetricRange, error) {
+		return c.next.GetCatalogMetricRange(ctx, scope, name, node, containerName, rng)
+	})
+}
+
+func (c *cachedInfraService) GetLogMetricsRange(ctx context.Context, rng domain.TimeRange, namespace, app string) (*domain.LogMetricsRange, error) {
+	key := strings.Join([]string{rangeKey(rng), namespace, app}, "|")
+	return cached(c, ctx, "GetLogMetricsRange", key, func(ctx context.Context) (*domain.LogMetricsRange, error) {
+		return c.next.GetLogMetricsRange(ctx, rng, namespace, app)
+	})
+}
+
+func (c *cachedInfraService) ListAlertGroups(ctx context.Context) ([]domain.AlertGroup, error) {
+	return cached(c, ctx, "ListAlertGroups", "", c.next.ListAlertGroups)
+}
+
+func (c *cachedInfraService) ListSilences(ctx context.Context) ([]domain.Silence, error) {
+	return cached(c, ctx, "ListSilences", "", c.next.ListSilences)
+}
+
+func (c *cachedInfraService) CreateSilence(ctx context.Context, silence domain.Silence) (string, error) {
+	id, err := c.next.CreateSilence(ctx, silence)
+	c.invalidate("ListSilences", "ListAlertGroups")
+	return id, err
+}
+
+func (c *cachedInfraService) ExpireSilence(ctx context.Context, id string) error {
+	err := c.next.ExpireSilence(ctx, id)
+	c.invalidate("ListSilences", "ListAlertGroups")
+	return err
+}
+
+func (c *cachedInfraService) ListDependencies(ctx context.Context) ([]domain.AppDependency, error) {
+	return cached(c, ctx, "ListDependencies", "", c.next.ListDependencies)
+}
+
+func (c *cachedInfraService) ListNodes(ctx context.Context) ([]domain.NodeInfo, error) {
+	return cached(c, ctx, "ListNodes", "", c.next.ListNodes)
+}
+
+func (c *cachedInfraService) DetectAnomalies(ctx context.Context, rng domain.TimeRange) (*domain.OverwatchInsight, error) {
+	return cached(c, ctx, "DetectAnomalies", rangeKey(rng), func(ctx context.Context) (*domain.OverwatchInsight, error) {
+		return c.next.DetectAnomalies(ctx, rng)
+	})
+}
+
+func (c *cachedInfraService) GetOverwatchInsights(ctx context.Context) (*domain.OverwatchInsight, error) {
+	return cached(c, ctx, "GetOverwatchInsights", "", c.next.GetOverwatchInsights)
+}
+
+func (c *cachedInfraService) GetPodInsights(ctx context.Context, namespace, app string) (*domain.PodInsight, error) {
+	return cached(c, ctx, "GetPodInsights", namespace+"|"+app, func(ctx context.Context) (*domain.PodInsight, error) {
+		return c.next.GetPodInsights(ctx, namespace, app)
+	})
+}
+
+func (c *cachedInfraService) GetAllPodInsights(ctx context.Context) ([]domain.PodInsight, error) {
+	return cached(c, ctx, "GetAllPodInsights", "", c.next.GetAllPodInsights)
+}
+
+func (c *cachedInfraService) GetOverwatchHistory(ctx context.Context, q domain.HistoryQuery) (*domain.HistoryPage, error) {
+	key := fmt.Sprintf("%d|%d|%d|%s", q.From.Unix(), q.To.Unix(), q.Limit, q.Cursor)
+	return cached(c, ctx, "GetOverwatchHistory", key, func(ctx context.Context) (*domain.HistoryPage, error) {
+		return c.next.GetOverwatchHistory(ctx, q)
+	})
+}
+
+func (c *cachedInfraService) DiffOverwatchInsights(ctx context.Context, from, to time.Time) (*domain.InsightDiff, error) {
+	key := fmt.Sprintf("%d|%d", from.Unix(), to.Unix())
+	return cached(c, ctx, "DiffOverwatchInsights", key, func(ctx context.Context) (*domain.InsightDiff, error) {
+		return c.next.DiffOverwatchInsights(ctx, from, to)
+	})
+}
+
+func (c *cachedInfraService) StartPodAnalysis(ctx context.Context, namespace, app string) (*domain.Job, error) {
+	return c.next.StartPodAnalysis(ctx, namespace, app)
+}
+
+func (c *cachedInfraService) StartClusterRefresh(ctx context.Context) (*domain.Job, error) {
+	return c.next.StartClusterRefresh(ctx)
+}
+
+func (c *cachedInfraService) GetJob(ctx context.Context, id string) (*domain.Job, error) {
+	return c.next.GetJob(ctx, id)
+}
+
+// Acks change how insights are annotated, so cached insights are dropped.
+func (c *cachedInfraService) AcknowledgeAnomaly(ctx context.Context, ack domain.AnomalyAck) (*domain.AnomalyAck, error) {
+	saved, err := c.next.AcknowledgeAnomaly(ctx, ack)
+	c.invalidate("GetOverwatchInsights", "DetectAnomalies", "GetOverwatchHistory", "GetEntityInsights", "DiffOverwatchInsights")
+	return saved, err
+}
+
+func (c *cachedInfraService) ListAnomalyAcks(ctx context.Context) ([]domain.AnomalyAck, error) {
+	return c.next.ListAnomalyAcks(ctx)
+}
+
+func (c *cachedInfraService) DeleteAnomalyAck(ctx context.Context, fingerprint string) error {
+	err := c.next.DeleteAnomalyAck(ctx, fingerprint)
+	c.invalidate("GetOverwatchInsights", "DetectAnomalies", "GetOverwatchHistory", "GetEntityInsights", "DiffOverwatchInsights")
+	return err
+}
+
+func (c *cachedInfraService) GetEntityInsights(ctx context.Context, id string) (*domain.EntityInsights, error) {
+	return cached(c, ctx, "GetEntityInsights", id, func(ctx context.Context) (*domain.EntityInsights, error) {
+		return c.next.GetEntityInsights(ctx, id)
+	})
+}
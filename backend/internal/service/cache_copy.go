@@ -0,0 +1,94 @@
+package service
+
+import "reflect"
+
+// deepCopy returns v with every pointer, slice, map and interface it reaches
+// copied. Unexported fields are copied as they are; the domain types keep
+// nothing mutable in them (time.Time's location is shared and immutable).
+func deepCopy[T any](v T) T {
+	rv := reflect.ValueOf(&v).Elem()
+	out := reflect.New(rv.Type()).Elem()
+	out.Set(copyValue(rv))
+	return out.Interface().(T)
+}
+
+func copyValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		p := reflect.New(v.Type().Elem())
+		p.Elem().Set(copyValue(v.Elem()))
+		return p
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		i := reflect.New(v.Type()).Elem()
+		i.Set(copyValue(v.Elem()))
+		return i
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		if !hasReferences(v.Type().Elem()) {
+			reflect.Copy(s, v)
+			return s
+		}
+		for i := 0; i < v.Len(); i++ {
+			s.Index(i).Set(copyValue(v.Index(i)))
+		}
+		return s
+	case reflect.Array:
+		a := reflect.New(v.Type()).Elem()
+		a.Set(v)
+		if hasReferences(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				a.Index(i).Set(copyValue(v.Index(i)))
+			}
+		}
+		return a
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		m := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for it := v.MapRange(); it.Next(); {
+			m.SetMapIndex(it.Key(), copyValue(it.Value()))
+		}
+		return m
+	case reflect.Struct:
+		s := reflect.New(v.Type()).Elem()
+		s.Set(v)
+		if !hasReferences(v.Type()) {
+			return s
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				s.Field(i).Set(copyValue(v.Field(i)))
+			}
+		}
+		return s
+	}
+	return v
+}
+
+// hasReferences reports whether a value of type t can share memory through
+// its exported parts, so that a plain copy isn't enough.
+func hasReferences(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+		return true
+	case reflect.Array:
+		return hasReferences(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() && hasReferences(f.Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
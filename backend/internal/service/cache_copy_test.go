@@ -0,0 +1,89 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+func TestDeepCopy(t *testing.T) {
+	at := time.Unix(1_700_000_000, 0)
+	insight := &domain.OverwatchInsight{
+		CollectedAt: &at,
+		Anomalies: []domain.OverwatchAnomaly{{
+			Type:     "cpu_spike",
+			Entities: []string{"app:media/jellyfin"},
+			Ack:      &domain.AnomalyAck{Fingerprint: "fp"},
+		}},
+		Recommendations: []string{"scale up"},
+	}
+	job := domain.Job{ID: "j1", Result: insight}
+	groups := []domain.AlertGroup{{Labels: map[string]string{"alertname": "HostDown"}}}
+	points := []domain.MetricPoint{{Timestamp: 1, Value: 2}}
+
+	tests := []struct {
+		name   string
+		change func()
+		check  func(t *testing.T)
+	}{
+		{
+			name: "pointer to struct with slices",
+			change: func() {
+				c := deepCopy(insight)
+				*c.CollectedAt = at.Add(time.Hour)
+				c.Anomalies[0].Hidden = true
+				c.Anomalies[0].Entities[0] = "changed"
+				c.Anomalies[0].Ack.Fingerprint = "changed"
+				c.Recommendations[0] = "changed"
+			},
+			check: func(t *testing.T) {
+				a := insight.Anomalies[0]
+				if !insight.CollectedAt.Equal(at) || a.Hidden || a.Entities[0] != "app:media/jellyfin" || a.Ack.Fingerprint != "fp" || insight.Recommendations[0] != "scale up" {
+					t.Errorf("original changed: %+v", insight)
+				}
+			},
+		},
+		{
+			name:   "interface field",
+			change: func() { deepCopy(job).Result.(*domain.OverwatchInsight).Status = "critical" },
+			check: func(t *testing.T) {
+				if insight.Status != "" {
+					t.Errorf("original status = %q", insight.Status)
+				}
+			},
+		},
+		{
+			name:   "map in slice",
+			change: func() { deepCopy(groups)[0].Labels["alertname"] = "changed" },
+			check: func(t *testing.T) {
+				if groups[0].Labels["alertname"] != "HostDown" {
+					t.Errorf("original labels = %v", groups[0].Labels)
+				}
+			},
+		},
+		{
+			name:   "flat slice",
+			change: func() { deepCopy(points)[0].Value = 9 },
+			check: func(t *testing.T) {
+				if points[0].Value != 2 {
+					t.Errorf("original point = %+v", points[0])
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.change()
+			tt.check(t)
+		})
+	}
+
+	var nilInsight *domain.OverwatchInsight
+	if deepCopy(nilInsight) != nil {
+		t.Error("copy of nil pointer is not nil")
+	}
+	if c := deepCopy([]string(nil)); c != nil {
+		t.Errorf("copy of nil slice = %#v", c)
+	}
+}
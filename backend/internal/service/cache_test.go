@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+	portin "github.com/isaacwallace123/portfolio-infra/internal/core/ports/in"
+)
+
+// countingService answers GetContainerStats, counting calls. When release is
+// set, every call waits on it.
+type countingService struct {
+	portin.InfraService
+	calls   atomic.Int32
+	release chan struct{}
+	err     error
+}
+
+func (s *countingService) GetContainerStats(ctx context.Context, id string) (*domain.ContainerStats, error) {
+	s.calls.Add(1)
+	if s.release != nil {
+		<-s.release
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.ContainerStats{CPUPercent: float64(len(id))}, nil
+}
+
+func newTestCache(next portin.InfraService, ttl time.Duration, maxEntries int) *cachedInfraService {
+	return NewCachedInfraService(next, CacheConfig{
+		MaxEntries: maxEntries,
+		TTLs:       map[string]time.Duration{"GetContainerStats": ttl},
+	}).(*cachedInfraService)
+}
+
+func TestParseCacheTTLs(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		method  string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "empty keeps defaults", spec: "", method: "ListContainers", want: 15 * time.Second},
+		{name: "override", spec: "ListContainers=30s", method: "ListContainers", want: 30 * time.Second},
+		{name: "zero disables", spec: " GetSystemInfo = 0 ", method: "GetSystemInfo", want: 0},
+		{name: "several", spec: "ListNodes=2m,,ListSilences=1s", method: "ListSilences", want: time.Second},
+		{name: "unknown method", spec: "CreateSilence=1m", wantErr: true},
+		{name: "missing value", spec: "ListContainers", wantErr: true},
+		{name: "negative", spec: "ListContainers=-1s", wantErr: true},
+		{name: "not a duration", spec: "ListContainers=soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ttls, err := ParseCacheTTLs(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseCacheTTLs(%q) = nil error, want one", tt.spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCacheTTLs(%q): %v", tt.spec, err)
+			}
+			if got := ttls[tt.method]; got != tt.want {
+				t.Errorf("TTL of %s = %s, want %s", tt.method, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCachedHitsAndExpiry(t *testing.T) {
+	tests := []struct {
+		name      string
+		ttl       time.Duration
+		wait      time.Duration
+		wantCalls int32
+		wantHits  uint64
+	}{
+		{name: "second call is a hit", ttl: time.Minute, wantCalls: 1, wantHits: 1},
+		{name: "expired entry is fetched again", ttl: 20 * time.Millisecond, wait: 40 * time.Millisecond, wantCalls: 2},
+		{name: "zero TTL passes through", ttl: 0, wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &countingService{}
+			c := newTestCache(next, tt.ttl, 0)
+			ctx := context.Background()
+
+			if _, err := c.GetContainerStats(ctx, "ns/pod"); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(tt.wait)
+			if _, err := c.GetContainerStats(ctx, "ns/pod"); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := next.calls.Load(); got != tt.wantCalls {
+				t.Errorf("backend calls = %d, want %d", got, tt.wantCalls)
+			}
+			if got := c.CacheStats().Hits; got != tt.wantHits {
+				t.Errorf("hits = %d, want %d", got, tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestCachedErrorsAreNotCached(t *testing.T) {
+	next := &countingService{err: errors.New("backend down")}
+	c := newTestCache(next, time.Minute, 0)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetContainerStats(context.Background(), "ns/pod"); err == nil {
+			t.Fatal("want the backend error")
+		}
+	}
+	if got := next.calls.Load(); got != 2 {
+		t.Errorf("backend calls = %d, want 2", got)
+	}
+	if got := c.CacheStats().Entries; got != 0 {
+		t.Errorf("entries = %d, want 0", got)
+	}
+}
+
+func TestCachedCoalescesConcurrentCalls(t *testing.T) {
+	const callers = 10
+	next := &countingService{release: make(chan struct{})}
+	c := newTestCache(next, time.Minute, 0)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.GetContainerStats(context.Background(), "ns/pod")
+			errs <- err
+		}()
+	}
+	// Give every caller time to join the in-flight fetch.
+	time.Sleep(50 * time.Millisecond)
+	close(next.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := next.calls.Load(); got != 1 {
+		t.Errorf("backend calls = %d, want 1", got)
+	}
+	stats := c.CacheStats()
+	if stats.Misses != 1 || stats.Coalesced != callers-1 {
+		t.Errorf("misses = %d, coalesced = %d; want 1 and %d", stats.Misses, stats.Coalesced, callers-1)
+	}
+}
+
+func TestCachedEvictsWhenFull(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxEntries    int
+		ids           []string
+		wantEntries   int
+		wantEvictions uint64
+	}{
+		{name: "under the limit", maxEntries: 3, ids: []string{"a", "b", "c"}, wantEntries: 3},
+		{name: "one over", maxEntries: 2, ids: []string{"a", "b", "c"}, wantEntries: 2, wantEvictions: 1},
+		{name: "repeat keys don't evict", maxEntries: 2, ids: []string{"a", "b", "a", "b"}, wantEntries: 2},
+		{name: "many over", maxEntries: 1, ids: []string{"a", "b", "c", "d"}, wantEntries: 1, wantEvictions: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCache(&countingService{}, time.Minute, tt.maxEntries)
+			for _, id := range tt.ids {
+				if _, err := c.GetContainerStats(context.Background(), id); err != nil {
+					t.Fatal(err)
+				}
+			}
+			stats := c.CacheStats()
+			if stats.Entries != tt.wantEntries || stats.Evictions != tt.wantEvictions {
+				t.Errorf("entries = %d, evictions = %d; want %d and %d", stats.Entries, stats.Evictions, tt.wantEntries, tt.wantEvictions)
+			}
+		})
+	}
+}
+
+func TestCachedInvalidate(t *testing.T) {
+	next := &countingService{}
+	c := newTestCache(next, time.Minute, 0)
+	ctx := context.Background()
+
+	c.GetContainerStats(ctx, "ns/pod")
+	c.invalidate("ListContainers")
+	c.GetContainerStats(ctx, "ns/pod")
+	if got := next.calls.Load(); got != 1 {
+		t.Fatalf("invalidating another method refetched: calls = %d", got)
+	}
+	c.invalidate("GetContainerStats")
+	c.GetContainerStats(ctx, "ns/pod")
+	if got := next.calls.Load(); got != 2 {
+		t.Errorf("backend calls after invalidate = %d, want 2", got)
+	}
+}
+
+func TestCachedReturnsCopies(t *testing.T) {
+	c := newTestCache(&countingService{}, time.Minute, 0)
+	ctx := context.Background()
+
+	first, err := c.GetContainerStats(ctx, "ns/pod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.CPUPercent = 99
+	second, err := c.GetContainerStats(ctx, "ns/pod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second == first || second.CPUPercent != float64(len("ns/pod")) {
+		t.Errorf("second call = %+v, want an unchanged copy", second)
+	}
+}
+
+func TestCachedFetchOutlivesCaller(t *testing.T) {
+	next := &countingService{release: make(chan struct{})}
+	c := newTestCache(next, time.Minute, 0)
+
+	// Leader and follower both go away before the fetch finishes.
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+		if _, err := c.GetContainerStats(ctx, "ns/pod"); !errors.Is(err, context.Canceled) {
+			t.Fatalf("call %d: err = %v, want context.Canceled", i, err)
+		}
+	}
+	close(next.release)
+
+	// The shared fetch still completes and fills the cache.
+	deadline := time.Now().Add(time.Second)
+	for c.CacheStats().Entries == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("fetch never stored its result")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := c.GetContainerStats(context.Background(), "ns/pod"); err != nil {
+		t.Fatal(err)
+	}
+	if got := next.calls.Load(); got != 1 {
+		t.Errorf("backend calls = %d, want 1", got)
+	}
+}
@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+// entityIndexTTL is how long the entity index built from the cluster is
+// reused when resolving anomalies.
+const entityIndexTTL = 30 * time.Second
+
+// entityIndex maps names as they appear in free-form text onto entity IDs.
+type entityIndex struct {
+	pods      map[string]domain.ContainerInfo // "ns/pod"
+	podByName map[string][]string             // pod name -> "ns/pod"
+	podApp    map[string]string               // "ns/pod" -> "ns/app"
+	apps      map[string]bool                 // "ns/app"
+	appByName map[string][]string             // app name -> "ns/app"
+	nodes     map[string]bool
+	deps      []domain.AppDependency
+	errors    []string
+}
+
+// entityIndexCache holds the last index built. Concurrent rebuilds share one
+// set of cluster calls, made without holding mu.
+type entityIndexCache struct {
+	group singleflight.Group
+
+	mu    sync.Mutex
+	index *entityIndex
+	built time.Time
+}
+
+// entityIndex returns the cached index, rebuilding it once the TTL is up. An
+// index is never changed after it is built, so callers can share it.
+func (s *infraService) entityIndex(ctx context.Context) (*entityIndex, error) {
+	s.entities.mu.Lock()
+	idx, fresh := s.entities.index, time.Since(s.entities.built) < entityIndexTTL
+	s.entities.mu.Unlock()
+	if idx != nil && fresh {
+		return idx, nil
+	}
+
+	ch := s.entities.group.DoChan("index", func() (any, error) {
+		buildCtx, cancel := fetchContext(ctx)
+		defer cancel()
+		idx, err := s.buildEntityIndex(buildCtx)
+		if err != nil {
+			return nil, err
+		}
+		s.entities.mu.Lock()
+		s.entities.index, s.entities.built = idx, time.Now()
+		s.entities.mu.Unlock()
+		return idx, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*entityIndex), nil
+	}
+}
+
+// buildEntityIndex lists pods, nodes and dependencies. Only the pod list is
+// required; the other failures are recorded on the index.
+func (s *infraService) buildEntityIndex(ctx context.Context) (*entityIndex, error) {
+	containers, err := s.cluster.ListContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	idx := &entityIndex{
+		pods:      make(map[string]domain.ContainerInfo),
+		podByName: make(map[string][]string),
+		podApp:    make(map[string]string),
+		apps:      make(map[string]bool),
+		appByName: make(map[string][]string),
+		nodes:     make(map[string]bool),
+	}
+	addApp := func(namespace, app string) string {
+		key := namespace + "/" + app
+		if !idx.apps[key] {
+			idx.apps[key] = true
+			idx.appByName[app] = append(idx.appByName[app], key)
+		}
+		return key
+	}
+	for _, c := range containers {
+		namespace, _, _ := strings.Cut(c.ID, "/")
+		idx.pods[c.ID] = c
+		idx.podByName[c.Name] = append(idx.podByName[c.Name], c.ID)
+		if c.AppName != "" {
+			idx.podApp[c.ID] = addApp(namespace, c.AppName)
+		}
+	}
+
+	if nodes, err := s.cluster.ListNodes(ctx); err != nil {
+		idx.errors = append(idx.errors, fmt.Sprintf("nodes: %v", err))
+	} else {
+		for _, n := range nodes {
+			idx.nodes[n.Name] = true
+		}
+	}
+	if deps, err := s.cluster.ListDependencies(ctx); err != nil {
+		idx.errors = append(idx.errors, fmt.Sprintf("dependencies: %v", err))
+	} else {
+		idx.deps = deps
+		for _, d := range deps {
+			addApp(d.SourceNamespace, d.SourceApp)
+			addApp(d.TargetNamespace, d.TargetApp)
+		}
+	}
+
+	return idx, nil
+}
+
+// resolve maps a free-form Affected string onto entity IDs. Each word is
+// tried as a pod ID or name, an app as "ns/app" or a bare name, a node name,
+// or an entity ID; a pod also resolves to its app.
+func (idx *entityIndex) resolve(affected string) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	addPod := func(podID string) {
+		ns, name, _ := strings.Cut(podID, "/")
+		add(domain.PodEntityID(ns, name))
+		if app, ok := idx.podApp[podID]; ok {
+			ns, name, _ := strings.Cut(app, "/")
+			add(domain.AppEntityID(ns, name))
+		}
+	}
+
+	tokens := strings.FieldsFunc(strings.ToLower(affected), func(r rune) bool {
+		return strings.ContainsRune(" \t\n,;()[]{}\"'`", r)
+	})
+	for _, tok := range tokens {
+		tok = strings.TrimRight(tok, ".:")
+		if tok == domain.EntityCluster {
+			add(domain.EntityCluster)
+			continue
+		}
+		if kind, name, ok := domain.ParseEntityID(tok); ok && kind != domain.EntityCluster {
+			tok = name
+		}
+		switch {
+		case idx.pods[tok].ID != "":
+			addPod(tok)
+		case idx.apps[tok]:
+			ns, name, _ := strings.Cut(tok, "/")
+			add(domain.AppEntityID(ns, name))
+		case idx.nodes[tok]:
+			add(domain.NodeEntityID(tok))
+		case len(idx.podByName[tok]) > 0:
+			for _, podID := range idx.podByName[tok] {
+				addPod(podID)
+			}
+		case len(idx.appByName[tok]) > 0:
+			for _, app := range idx.appByName[tok] {
+				ns, name, _ := strings.Cut(app, "/")
+				add(domain.AppEntityID(ns, name))
+			}
+		}
+	}
+	return ids
+}
+
+// exists reports whether the index knows the entity.
+func (idx *entityIndex) exists(kind, name string) bool {
+	switch kind {
+	case domain.EntityCluster:
+		return true
+	case domain.EntityNode:
+		return idx.nodes[name]
+	case domain.EntityApp:
+		return idx.apps[name]
+	case domain.EntityPod:
+		return idx.pods[name].ID != ""
+	}
+	return false
+}
+
+// GetEntityInsights collects the anomalies, pod insights and alerts touching
+// an entity, its pods or app, and the apps upstream and downstream of it.
+// Sources that fail are reported in Errors rather than failing the call.
+func (s *infraService) GetEntityInsights(ctx context.Context, id string) (*domain.EntityInsights, error) {
+	kind, name, ok := domain.ParseEntityID(id)
+	if !ok {
+		return nil, domain.ErrEntityNotFound
+	}
+	idx, err := s.entityIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !idx.exists(kind, name) {
+		return nil, domain.ErrEntityNotFound
+	}
+
+	result := &domain.EntityInsights{
+		ID:          id,
+		Kind:        kind,
+		Related:     []string{},
+		Upstream:    []string{},
+		Downstream:  []string{},
+		Anomalies:   []domain.EntityAnomaly{},
+		PodInsights: []domain.EntityPodInsight{},
+		Alerts:      []domain.EntityAlert{},
+		Errors:      append([]string(nil), idx.errors...),
+	}
+
+	// via maps every entity whose items are included to how it relates; the
+	// entity itself maps to "".
+	via := map[string]string{id: ""}
+	relate := func(entityID string) {
+		if _, ok := via[entityID]; !ok {
+			via[entityID] = entityID
+			result.Related = append(result.Related, entityID)
+		}
+	}
+
+	app := ""
+	switch kind {
+	case domain.EntityApp:
+		app = name
+	case domain.EntityPod:
+		app = idx.podApp[name]
+		if app != "" {
+			ns, n, _ := strings.Cut(app, "/")
+			relate(domain.AppEntityID(ns, n))
+		}
+	}
+	if kind == domain.EntityApp {
+		for podID, podApp := range idx.podApp {
+			if podApp == app {
+				ns, n, _ := strings.Cut(podID, "/")
+				relate(domain.PodEntityID(ns, n))
+			}
+		}
+	}
+	if app != "" {
+		for _, d := range idx.deps {
+			source := d.SourceNamespace + "/" + d.SourceApp
+			target := d.TargetNamespace + "/" + d.TargetApp
+			switch app {
+			case source:
+				up := domain.AppEntityID(d.TargetNamespace, d.TargetApp)
+				result.Upstream = append(result.Upstream, up)
+				relate(up)
+			case target:
+				down := domain.AppEntityID(d.SourceNamespace, d.SourceApp)
+				result.Downstream = append(result.Downstream, down)
+				relate(down)
+			}
+		}
+	}
+
+	s.collectEntityAnomalies(ctx, result, via)
+
+	if insights, err := s.GetAllPodInsights(ctx); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("pod insights: %v", err))
+	} else {
+		for _, pi := range insights {
+			if v, ok := via[pi.Entity]; ok {
+				result.PodInsights = append(result.PodInsights, domain.EntityPodInsight{PodInsight: pi, Via: v})
+			}
+		}
+	}
+
+	if groups, err := s.ListAlertGroups(ctx); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("alerts: %v", err))
+	} else {
+		for _, g := range groups {
+			for _, a := range g.Alerts {
+				if v, ok := alertVia(a, idx, via); ok {
+					result.Alerts = append(result.Alerts, domain.EntityAlert{Alert: a, Via: v})
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// collectEntityAnomalies adds anomalies from the latest insight and recent
+// history, newest occurrence of each fingerprint only.
+func (s *infraService) collectEntityAnomalies(ctx context.Context, result *domain.EntityInsights, via map[string]string) {
+	var insights []domain.OverwatchInsight
+	if latest, err := s.GetOverwatchInsights(ctx); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("insights: %v", err))
+	} else {
+		insights = append(insights, *latest)
+	}
+	if page, err := s.GetOverwatchHistory(ctx, domain.HistoryQuery{Limit: entityHistoryLimit}); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("history: %v", err))
+	} else {
+		insights = append(insights, page.Items...)
+	}
+
+	seen := make(map[string]bool)
+	for _, in := range insights {
+		for _, a := range in.Anomalies {
+			if seen[a.Fingerprint] {
+				continue
+			}
+			for _, e := range a.Entities {
+				if v, ok := via[e]; ok {
+					seen[a.Fingerprint] = true
+					result.Anomalies = append(result.Anomalies, domain.EntityAnomaly{
+						OverwatchAnomaly: a, CollectedAt: in.CollectedAt, Source: in.Source, Via: v,
+					})
+					break
+				}
+			}
+		}
+	}
+}
+
+// entityHistoryLimit is how many past insights are searched for anomalies.
+const entityHistoryLimit = 48
+
+func alertVia(a domain.Alert, idx *entityIndex, via map[string]string) (string, bool) {
+	var candidates []string
+	if a.ContainerID != "" {
+		ns, pod, _ := strings.Cut(a.ContainerID, "/")
+		candidates = append(candidates, domain.PodEntityID(ns, pod))
+		if app, ok := idx.podApp[a.ContainerID]; ok {
+			ns, n, _ := strings.Cut(app, "/")
+			candidates = append(candidates, domain.AppEntityID(ns, n))
+		}
+	}
+	if a.Node != "" {
+		candidates = append(candidates, domain.NodeEntityID(a.Node))
+	}
+	for _, c := range candidates {
+		if v, ok := via[c]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// resolveEntities fills Entities on every anomaly. Resolution is best effort:
+// without the cluster index anomalies are left unresolved.
+func (s *infraService) resolveEntities(ctx context.Context, insights []*domain.OverwatchInsight) {
+	idx, err := s.entityIndex(ctx)
+	if err != nil {
+		return
+	}
+	for _, insight := range insights {
+		if insight == nil {
+			continue
+		}
+		for i := range insight.Anomalies {
+			insight.Anomalies[i].Entities = idx.resolve(insight.Anomalies[i].Affected)
+		}
+	}
+}
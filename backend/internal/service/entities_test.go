@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+// blockingCluster counts pod listings and holds each one until release is
+// closed, failing it when fail is set.
+type blockingCluster struct {
+	emptyCluster
+	calls   atomic.Int32
+	release chan struct{}
+	fail    bool
+}
+
+func (c *blockingCluster) ListContainers(ctx context.Context) ([]domain.ContainerInfo, error) {
+	c.calls.Add(1)
+	select {
+	case <-c.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if c.fail {
+		return nil, errors.New("cluster unavailable")
+	}
+	return []domain.ContainerInfo{{ID: "media/jellyfin-0", Name: "jellyfin-0", AppName: "jellyfin"}}, nil
+}
+
+func TestEntityIndexSharesOneBuild(t *testing.T) {
+	cluster := &blockingCluster{release: make(chan struct{})}
+	s := NewInfraService(cluster, nil, nil, nil, nil, nil).(*infraService)
+
+	const callers = 8
+	results := make([]*entityIndex, callers)
+	var wg sync.WaitGroup
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			idx, err := s.entityIndex(context.Background())
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = idx
+		}()
+	}
+
+	// The lock is free while the build waits on the cluster. Other callers
+	// take it briefly to check the cache, so retry until the deadline.
+	deadline := time.Now().Add(time.Second)
+	for cluster.calls.Load() == 0 || !s.entities.mu.TryLock() {
+		if time.Now().After(deadline) {
+			t.Fatal("entities lock held during the cluster calls")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	s.entities.mu.Unlock()
+
+	close(cluster.release)
+	wg.Wait()
+	if n := cluster.calls.Load(); n != 1 {
+		t.Errorf("ListContainers called %d times, want 1", n)
+	}
+	for _, idx := range results {
+		if idx == nil || idx != results[0] || !idx.exists(domain.EntityPod, "media/jellyfin-0") {
+			t.Fatalf("results = %v, want one shared index with media/jellyfin-0", results)
+		}
+	}
+
+	// A fresh index is reused without calling the cluster.
+	if _, err := s.entityIndex(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := cluster.calls.Load(); n != 1 {
+		t.Errorf("ListContainers called %d times after a cache hit, want 1", n)
+	}
+}
+
+func TestEntityIndexFailedBuildIsNotCached(t *testing.T) {
+	cluster := &blockingCluster{release: make(chan struct{}), fail: true}
+	close(cluster.release)
+	s := NewInfraService(cluster, nil, nil, nil, nil, nil).(*infraService)
+
+	for range 2 {
+		if _, err := s.entityIndex(context.Background()); err == nil {
+			t.Fatal("entityIndex succeeded, want the cluster error")
+		}
+	}
+	if n := cluster.calls.Load(); n != 2 {
+		t.Errorf("ListContainers called %d times, want 2", n)
+	}
+	if s.entities.index != nil {
+		t.Error("failed build was cached")
+	}
+}
+
+func TestEntityIndexCallerCancel(t *testing.T) {
+	cluster := &blockingCluster{release: make(chan struct{})}
+	s := NewInfraService(cluster, nil, nil, nil, nil, nil).(*infraService)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := s.entityIndex(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+
+	// The build outlives the caller and still fills the cache.
+	close(cluster.release)
+	deadline := time.Now().Add(time.Second)
+	for {
+		s.entities.mu.Lock()
+		idx := s.entities.index
+		s.entities.mu.Unlock()
+		if idx != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("index was not stored after the caller left")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+// minForecastPoints is the fewest samples a trend is fitted to.
+const minForecastPoints = 3
+
+// ForecastCapacity fits a least-squares line to each filesystem, PVC and node
+// memory series over q.Range and projects when it crosses q.Threshold percent.
+// Resources projected to cross within q.Horizon are flagged AtRisk and sorted
+// first, soonest at the top.
+func (s *infraService) ForecastCapacity(ctx context.Context, q domain.ForecastQuery) (*domain.CapacityReport, error) {
+	data, err := s.metrics.GetCapacityRange(ctx, q.Range)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.CapacityReport{
+		HorizonSeconds: int64(q.Horizon / time.Second),
+		Threshold:      q.Threshold,
+		Forecasts:      []domain.CapacityForecast{},
+		Warnings:       data.Warnings,
+		Errors:         data.Errors,
+	}
+
+	add := func(kind string, series []domain.Series, name func(map[string]string) string) {
+		for _, sr := range series {
+			f, ok := forecastSeries(sr.Points, q)
+			if !ok {
+				continue
+			}
+			f.Kind = kind
+			f.Name = name(sr.Labels)
+			f.Labels = sr.Labels
+			report.Forecasts = append(report.Forecasts, f)
+		}
+	}
+	add(domain.CapacityKindFilesystem, data.Filesystems, func(l map[string]string) string {
+		if l["node"] == "" {
+			return l["mountpoint"]
+		}
+		return l["node"] + ":" + l["mountpoint"]
+	})
+	add(domain.CapacityKindPVC, data.PVCs, func(l map[string]string) string {
+		return l["namespace"] + "/" + l["persistentvolumeclaim"]
+	})
+	add(domain.CapacityKindMemory, data.Memory, func(l map[string]string) string {
+		return l["node"]
+	})
+
+	sort.SliceStable(report.Forecasts, func(i, j int) bool {
+		a, b := report.Forecasts[i], report.Forecasts[j]
+		if a.AtRisk != b.AtRisk {
+			return a.AtRisk
+		}
+		if (a.TimeToFullSeconds == nil) != (b.TimeToFullSeconds == nil) {
+			return a.TimeToFullSeconds != nil
+		}
+		if a.TimeToFullSeconds != nil && *a.TimeToFullSeconds != *b.TimeToFullSeconds {
+			return *a.TimeToFullSeconds < *b.TimeToFullSeconds
+		}
+		return a.Current > b.Current
+	})
+
+	return report, nil
+}
+
+// forecastSeries fits value = intercept + slope*t by least squares. Current is
+// the fitted value at the last sample, which smooths out a noisy final point.
+func forecastSeries(points []domain.MetricPoint, q domain.ForecastQuery) (domain.CapacityForecast, bool) {
+	if len(points) < minForecastPoints {
+		return domain.CapacityForecast{}, false
+	}
+
+	// Center time on the first sample to keep the sums well conditioned.
+	t0 := points[0].Timestamp
+	n := float64(len(points))
+	var sumX, sumY, sumXX, sumXY float64
+	for _, p := range points {
+		x := float64(p.Timestamp - t0)
+		sumX += x
+		sumY += p.Value
+		sumXX += x * x
+		sumXY += x * p.Value
+	}
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return domain.CapacityForecast{}, false
+	}
+	slope := (n*sumXY - sumX*sumY) / denom
+	intercept := (sumY - slope*sumX) / n
+
+	meanY := sumY / n
+	var ssTot, ssRes float64
+	for _, p := range points {
+		fit := intercept + slope*float64(p.Timestamp-t0)
+		ssRes += (p.Value - fit) * (p.Value - fit)
+		ssTot += (p.Value - meanY) * (p.Value - meanY)
+	}
+	r2 := 1.0
+	if ssTot > 0 {
+		r2 = 1 - ssRes/ssTot
+	}
+
+	last := points[len(points)-1]
+	current := intercept + slope*float64(last.Timestamp-t0)
+	f := domain.CapacityForecast{
+		Current:      round2(current),
+		GrowthPerDay: round2(slope * 86400),
+		R2:           round2(r2),
+	}
+
+	var secs float64
+	switch {
+	case current >= q.Threshold:
+		secs = 0
+	case slope > 0:
+		secs = (q.Threshold - current) / slope
+	default:
+		return f, true
+	}
+	if secs > float64(math.MaxInt64/int64(time.Second)) {
+		return f, true
+	}
+
+	ttf := int64(secs)
+	fullAt := time.Unix(last.Timestamp+ttf, 0).UTC()
+	f.TimeToFullSeconds = &ttf
+	f.FullAt = &fullAt
+	f.AtRisk = time.Duration(ttf)*time.Second <= q.Horizon
+	return f, true
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
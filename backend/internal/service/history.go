@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+// historyFetchLimit bounds what is fetched from Overwatch to cut pages from
+// when there is no store.
+const historyFetchLimit = 500
+
+// GetOverwatchHistory records whatever Overwatch returns for the query and,
+// when a store is configured, answers from the store so that history older
+// than Overwatch's own, or from while it is down, is included. Without a
+// store every page is cut from the newest historyFetchLimit insights in the
+// range, so paging doesn't rely on Overwatch honouring the cursor's bound.
+func (s *infraService) GetOverwatchHistory(ctx context.Context, q domain.HistoryQuery) (*domain.HistoryPage, error) {
+	fetch := q
+	if s.store == nil {
+		fetch = domain.HistoryQuery{From: q.From, To: q.To, Limit: historyFetchLimit}
+	}
+	remote, err := s.overwatch.GetHistory(ctx, fetch)
+	if err == nil {
+		for i := range remote {
+			s.recordInsight(ctx, &remote[i])
+		}
+	}
+
+	var page *domain.HistoryPage
+	switch {
+	case s.store != nil:
+		if err != nil {
+			log.Printf("[service] overwatch history unavailable, serving from store: %v", err)
+		}
+		page, err = s.store.ListInsights(ctx, q)
+	case err == nil:
+		page, err = pageInsights(remote, q)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*domain.OverwatchInsight, len(page.Items))
+	for i := range page.Items {
+		items[i] = &page.Items[i]
+	}
+	s.annotate(ctx, items...)
+	return page, nil
+}
+
+// pageInsights applies the query to insights from Overwatch, which may
+// ignore the range and knows nothing of cursors.
+func pageInsights(insights []domain.OverwatchInsight, q domain.HistoryQuery) (*domain.HistoryPage, error) {
+	upper, err := q.Upper()
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.HistoryPage{Items: []domain.OverwatchInsight{}}
+	for _, in := range insights {
+		if in.CollectedAt == nil {
+			continue
+		}
+		if !q.From.IsZero() && in.CollectedAt.Before(q.From) {
+			continue
+		}
+		if !upper.IsZero() && in.CollectedAt.After(upper) {
+			continue
+		}
+		page.Items = append(page.Items, in)
+	}
+	sort.SliceStable(page.Items, func(i, j int) bool {
+		return page.Items[i].CollectedAt.After(*page.Items[j].CollectedAt)
+	})
+
+	if q.Limit > 0 && len(page.Items) > q.Limit {
+		page.Items = page.Items[:q.Limit]
+		page.NextCursor = domain.HistoryCursor(*page.Items[q.Limit-1].CollectedAt)
+	}
+	return page, nil
+}
+
+func (s *infraService) recordInsight(ctx context.Context, insight *domain.OverwatchInsight) {
+	if s.store == nil || insight == nil {
+		return
+	}
+	if err := s.store.SaveInsight(ctx, *insight); err != nil {
+		log.Printf("[service] record insight: %v", err)
+	}
+}
+
+func (s *infraService) recordPodInsight(ctx context.Context, insight domain.PodInsight) {
+	if s.store == nil {
+		return
+	}
+	if err := s.store.SavePodInsight(ctx, insight); err != nil {
+		log.Printf("[service] record pod insight: %v", err)
+	}
+}
+
+// DiffOverwatchInsights compares the insights current at from and at to. A
+// zero to means the newest insight; a zero from means the one before to.
+func (s *infraService) DiffOverwatchInsights(ctx context.Context, from, to time.Time) (*domain.InsightDiff, error) {
+	limit := 1
+	if from.IsZero() {
+		limit = 2
+	}
+	page, err := s.GetOverwatchHistory(ctx, domain.HistoryQuery{To: to, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	if len(page.Items) == 0 {
+		return nil, domain.ErrSnapshotNotFound
+	}
+	newer := page.Items[0]
+
+	var older *domain.OverwatchInsight
+	if from.IsZero() {
+		if len(page.Items) > 1 {
+			older = &page.Items[1]
+		}
+	} else {
+		page, err := s.GetOverwatchHistory(ctx, domain.HistoryQuery{To: from, Limit: 1})
+		if err != nil {
+			return nil, err
+		}
+		if len(page.Items) == 0 {
+			return nil, domain.ErrSnapshotNotFound
+		}
+		older = &page.Items[0]
+	}
+
+	diff := domain.DiffInsights(older, newer)
+	return &diff, nil
+}
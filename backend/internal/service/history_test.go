@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+	portout "github.com/isaacwallace123/portfolio-infra/internal/core/ports/out"
+)
+
+var historyBase = time.Unix(1_700_000_000, 0)
+
+// insightsAt returns insights collected the given minutes after historyBase.
+func insightsAt(minutes ...int) []domain.OverwatchInsight {
+	out := make([]domain.OverwatchInsight, len(minutes))
+	for i, m := range minutes {
+		at := historyBase.Add(time.Duration(m) * time.Minute)
+		out[i].CollectedAt = &at
+	}
+	return out
+}
+
+func minutesOf(items []domain.OverwatchInsight) []int {
+	out := make([]int, len(items))
+	for i, in := range items {
+		out[i] = int(in.CollectedAt.Sub(historyBase) / time.Minute)
+	}
+	return out
+}
+
+func TestPageInsights(t *testing.T) {
+	// Out of order, as Overwatch may return them, with one undated insight.
+	insights := append(insightsAt(3, 1, 5, 2, 4), domain.OverwatchInsight{})
+
+	tests := []struct {
+		name  string
+		q     domain.HistoryQuery
+		pages [][]int
+	}{
+		{name: "no limit", q: domain.HistoryQuery{}, pages: [][]int{{5, 4, 3, 2, 1}}},
+		{name: "pages of two", q: domain.HistoryQuery{Limit: 2}, pages: [][]int{{5, 4}, {3, 2}, {1}}},
+		{name: "exact fit has no cursor", q: domain.HistoryQuery{Limit: 5}, pages: [][]int{{5, 4, 3, 2, 1}}},
+		{
+			name:  "range",
+			q:     domain.HistoryQuery{From: historyBase.Add(2 * time.Minute), To: historyBase.Add(4 * time.Minute), Limit: 2},
+			pages: [][]int{{4, 3}, {2}},
+		},
+		{name: "empty range", q: domain.HistoryQuery{From: historyBase.Add(time.Hour)}, pages: [][]int{{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.q
+			for i, want := range tt.pages {
+				page, err := pageInsights(insights, q)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := minutesOf(page.Items); !slices.Equal(got, want) {
+					t.Fatalf("page %d = %v, want %v", i, got, want)
+				}
+				last := i == len(tt.pages)-1
+				if last != (page.NextCursor == "") {
+					t.Fatalf("page %d cursor = %q, last page = %v", i, page.NextCursor, last)
+				}
+				q.Cursor = page.NextCursor
+			}
+		})
+	}
+
+	if _, err := pageInsights(insights, domain.HistoryQuery{Cursor: "x"}); err == nil {
+		t.Error("want an error for a bad cursor")
+	}
+}
+
+// newestOnly is an Overwatch that ignores the range and returns its newest
+// insights, up to one more than the limit, as the port allows.
+type newestOnly struct {
+	portout.OverwatchRepository
+	insights []domain.OverwatchInsight // newest first
+	queries  []domain.HistoryQuery
+}
+
+func (o *newestOnly) GetHistory(ctx context.Context, q domain.HistoryQuery) ([]domain.OverwatchInsight, error) {
+	o.queries = append(o.queries, q)
+	return o.insights[:min(q.Limit+1, len(o.insights))], nil
+}
+
+func TestGetOverwatchHistoryWithoutStore(t *testing.T) {
+	remote := &newestOnly{insights: insightsAt(5, 4, 3, 2, 1)}
+	s := NewInfraService(emptyCluster{}, nil, nil, nil, remote, nil)
+
+	q := domain.HistoryQuery{Limit: 2}
+	var got [][]int
+	for i := 0; i < 5; i++ {
+		page, err := s.GetOverwatchHistory(context.Background(), q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, minutesOf(page.Items))
+		if page.NextCursor == "" {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+	if want := [][]int{{5, 4}, {3, 2}, {1}}; !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("pages = %v, want %v", got, want)
+	}
+	for _, fq := range remote.queries {
+		if fq.Cursor != "" || fq.Limit != historyFetchLimit {
+			t.Errorf("fetched %+v, want a bounded fetch without cursor", fq)
+		}
+	}
+}
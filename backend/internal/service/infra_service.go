@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"log"
+	"time"
 
 	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
 	portin "github.com/isaacwallace123/portfolio-infra/internal/core/ports/in"
@@ -11,14 +14,26 @@ import (
 type infraService struct {
 	cluster   portout.ClusterRepository
 	metrics   portout.MetricsRepository
+	logs      portout.LogMetricsRepository
+	alerts    portout.AlertRepository
 	overwatch portout.OverwatchRepository
+	// store is optional; without it history comes from Overwatch alone.
+	store    portout.InsightStore
+	memAcks  *memoryAcks
+	entities entityIndexCache
+	jobs     *jobQueue
 }
 
-func NewInfraService(cluster portout.ClusterRepository, metrics portout.MetricsRepository, overwatch portout.OverwatchRepository) portin.InfraService {
+func NewInfraService(cluster portout.ClusterRepository, metrics portout.MetricsRepository, logs portout.LogMetricsRepository, alerts portout.AlertRepository, overwatch portout.OverwatchRepository, store portout.InsightStore) portin.InfraService {
 	return &infraService{
 		cluster:   cluster,
 		metrics:   metrics,
+		logs:      logs,
+		alerts:    alerts,
 		overwatch: overwatch,
+		store:     store,
+		memAcks:   newMemoryAcks(),
+		jobs:      newJobQueue(),
 	}
 }
 
@@ -46,16 +61,48 @@ func (s *infraService) GetSystemInfo(ctx context.Context) (*domain.SystemInfo, e
 	return s.cluster.GetSystemInfo(ctx)
 }
 
-func (s *infraService) GetNodeMetrics(ctx context.Context) (map[string]interface{}, error) {
-	return s.metrics.GetNodeMetrics(ctx)
+func (s *infraService) GetMetricsRange(ctx context.Context, rng domain.TimeRange, containerName string) (*domain.MetricsRange, error) {
+	return s.metrics.GetMetricsRange(ctx, rng, containerName)
 }
 
-func (s *infraService) GetMetricsRange(ctx context.Context, duration, containerName string) (*domain.MetricsRange, error) {
-	return s.metrics.GetMetricsRange(ctx, duration, containerName)
+func (s *infraService) GetNodeMetricsRange(ctx context.Context, node string, rng domain.TimeRange) (*domain.MetricsRange, error) {
+	return s.metrics.GetNodeMetricsRange(ctx, node, rng)
 }
 
-func (s *infraService) GetNodeMetricsRange(ctx context.Context, node, duration string) (*domain.MetricsRange, error) {
-	return s.metrics.GetNodeMetricsRange(ctx, node, duration)
+func (s *infraService) GetMetricsBreakdown(ctx context.Context, node string, rng domain.TimeRange) (*domain.MetricsBreakdown, error) {
+	return s.metrics.GetMetricsBreakdown(ctx, node, rng)
+}
+
+func (s *infraService) GetMetricsHealth(ctx context.Context) (*domain.MetricsHealth, error) {
+	return s.metrics.GetMetricsHealth(ctx)
+}
+
+func (s *infraService) ListMetricDefinitions(ctx context.Context) ([]domain.MetricDefinition, error) {
+	return s.metrics.ListMetricDefinitions(ctx)
+}
+
+func (s *infraService) GetCatalogMetricRange(ctx context.Context, scope, name, node, containerName string, rng domain.TimeRange) (*domain.CatalogMetricRange, error) {
+	return s.metrics.GetCatalogMetricRange(ctx, scope, name, node, containerName, rng)
+}
+
+func (s *infraService) GetLogMetricsRange(ctx context.Context, rng domain.TimeRange, namespace, app string) (*domain.LogMetricsRange, error) {
+	return s.logs.GetLogMetricsRange(ctx, rng, namespace, app)
+}
+
+func (s *infraService) ListAlertGroups(ctx context.Context) ([]domain.AlertGroup, error) {
+	return s.alerts.ListAlertGroups(ctx)
+}
+
+func (s *infraService) ListSilences(ctx context.Context) ([]domain.Silence, error) {
+	return s.alerts.ListSilences(ctx)
+}
+
+func (s *infraService) CreateSilence(ctx context.Context, silence domain.Silence) (string, error) {
+	return s.alerts.CreateSilence(ctx, silence)
+}
+
+func (s *infraService) ExpireSilence(ctx context.Context, id string) error {
+	return s.alerts.ExpireSilence(ctx, id)
 }
 
 func (s *infraService) ListDependencies(ctx context.Context) ([]domain.AppDependency, error) {
@@ -66,18 +113,58 @@ func (s *infraService) ListNodes(ctx context.Context) ([]domain.NodeInfo, error)
 	return s.cluster.ListNodes(ctx)
 }
 
+// GetOverwatchInsights returns Overwatch's latest insight, or the local
+// detector's findings when Overwatch can't be reached.
 func (s *infraService) GetOverwatchInsights(ctx context.Context) (*domain.OverwatchInsight, error) {
-	return s.overwatch.GetInsights(ctx)
+	insight, err := s.overwatch.GetInsights(ctx)
+	if err == nil {
+		s.recordInsight(ctx, insight)
+		s.annotate(ctx, insight)
+		return insight, nil
+	}
+
+	log.Printf("[service] overwatch unavailable, running local anomaly detection: %v", err)
+	now := time.Now()
+	local, lerr := s.DetectAnomalies(ctx, domain.TimeRange{Start: now.Add(-anomalyLookback), End: now, Step: anomalyStep})
+	if lerr != nil {
+		return nil, fmt.Errorf("%v; local detection: %v", err, lerr)
+	}
+	s.recordInsight(ctx, local)
+	return local, nil
 }
 
 func (s *infraService) GetPodInsights(ctx context.Context, namespace, app string) (*domain.PodInsight, error) {
-	return s.overwatch.GetPodInsights(ctx, namespace, app)
+	insight, err := s.overwatch.GetPodInsights(ctx, namespace, app)
+	if err != nil {
+		return nil, err
+	}
+	insight.Entity = domain.AppEntityID(insight.Namespace, insight.App)
+	s.recordPodInsight(ctx, *insight)
+	return insight, nil
 }
 
+// GetAllPodInsights returns Overwatch's latest insight per app, or the last
+// ones recorded locally when Overwatch can't be reached.
 func (s *infraService) GetAllPodInsights(ctx context.Context) ([]domain.PodInsight, error) {
-	return s.overwatch.GetAllPodInsights(ctx)
-}
+	insights, err := s.overwatch.GetAllPodInsights(ctx)
+	if err == nil {
+		for i := range insights {
+			insights[i].Entity = domain.AppEntityID(insights[i].Namespace, insights[i].App)
+			s.recordPodInsight(ctx, insights[i])
+		}
+		return insights, nil
+	}
+	if s.store == nil {
+		return nil, err
+	}
 
-func (s *infraService) GetOverwatchHistory(ctx context.Context) ([]domain.OverwatchInsight, error) {
-	return s.overwatch.GetHistory(ctx)
+	log.Printf("[service] overwatch unavailable, serving stored pod insights: %v", err)
+	insights, err = s.store.LatestPodInsights(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range insights {
+		insights[i].Entity = domain.AppEntityID(insights[i].Namespace, insights[i].App)
+	}
+	return insights, nil
 }
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+const (
+	// maxConcurrentJobs limits how many analyses Overwatch runs at once; the
+	// rest wait queued.
+	maxConcurrentJobs = 2
+	// jobRetention is how long finished jobs stay queryable.
+	jobRetention      = time.Hour
+	podJobTimeout     = 3 * time.Minute
+	clusterJobTimeout = time.Minute
+)
+
+// jobQueue runs analyses in the background. Requests for a target that
+// already has an unfinished job get that job back instead of a new one.
+type jobQueue struct {
+	sem chan struct{}
+
+	mu     sync.Mutex
+	jobs   map[string]*domain.Job
+	active map[string]string // dedupe key -> job ID
+	done   []func(domain.Job)
+}
+
+func newJobQueue() *jobQueue {
+	return &jobQueue{
+		sem:    make(chan struct{}, maxConcurrentJobs),
+		jobs:   make(map[string]*domain.Job),
+		active: make(map[string]string),
+	}
+}
+
+func (q *jobQueue) submit(job domain.Job, timeout time.Duration, run func(context.Context) (any, error)) domain.Job {
+	key := job.Kind + "|" + job.Namespace + "|" + job.App
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.prune()
+	if id, ok := q.active[key]; ok {
+		return *q.jobs[id]
+	}
+
+	job.ID = newJobID()
+	job.Status = domain.JobQueued
+	job.CreatedAt = time.Now().UTC()
+	q.jobs[job.ID] = &job
+	q.active[key] = job.ID
+
+	go q.run(job.ID, key, timeout, run)
+	return job
+}
+
+func (q *jobQueue) run(id, key string, timeout time.Duration, run func(context.Context) (any, error)) {
+	q.sem <- struct{}{}
+	defer func() { <-q.sem }()
+
+	q.update(id, func(j *domain.Job) {
+		now := time.Now().UTC()
+		j.Status, j.StartedAt = domain.JobRunning, &now
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	result, err := run(ctx)
+
+	q.mu.Lock()
+	delete(q.active, key)
+	j := q.jobs[id]
+	now := time.Now().UTC()
+	j.FinishedAt = &now
+	if err != nil {
+		log.Printf("[service] job %s (%s) failed: %v", id, j.Kind, err)
+		j.Status, j.Error = domain.JobFailed, err.Error()
+	} else {
+		j.Status, j.Result = domain.JobDone, result
+	}
+	finished, listeners := *j, q.done
+	q.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(finished)
+	}
+}
+
+func (q *jobQueue) onDone(fn func(domain.Job)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.done = append(q.done, fn)
+}
+
+func (q *jobQueue) update(id string, fn func(*domain.Job)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	fn(q.jobs[id])
+}
+
+func (q *jobQueue) get(id string) (domain.Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, ok := q.jobs[id]
+	if !ok {
+		return domain.Job{}, false
+	}
+	return *j, true
+}
+
+// prune drops finished jobs past their retention; must be called with q.mu held.
+func (q *jobQueue) prune() {
+	cutoff := time.Now().Add(-jobRetention)
+	for id, j := range q.jobs {
+		if j.FinishedAt != nil && j.FinishedAt.Before(cutoff) {
+			delete(q.jobs, id)
+		}
+	}
+}
+
+func newJobID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func (s *infraService) StartPodAnalysis(ctx context.Context, namespace, app string) (*domain.Job, error) {
+	job := s.jobs.submit(domain.Job{Kind: domain.JobKindPodInsight, Namespace: namespace, App: app}, podJobTimeout,
+		func(ctx context.Context) (any, error) {
+			return s.GetPodInsights(ctx, namespace, app)
+		})
+	return &job, nil
+}
+
+// StartClusterRefresh re-reads the cluster insight in the background, which
+// records it for history and lets the cache drop what it held.
+func (s *infraService) StartClusterRefresh(ctx context.Context) (*domain.Job, error) {
+	job := s.jobs.submit(domain.Job{Kind: domain.JobKindClusterInsight}, clusterJobTimeout,
+		func(ctx context.Context) (any, error) {
+			return s.GetOverwatchInsights(ctx)
+		})
+	return &job, nil
+}
+
+func (s *infraService) OnJobDone(fn func(domain.Job)) {
+	s.jobs.onDone(fn)
+}
+
+func (s *infraService) GetJob(ctx context.Context, id string) (*domain.Job, error) {
+	job, ok := s.jobs.get(id)
+	if !ok {
+		return nil, domain.ErrJobNotFound
+	}
+	return &job, nil
+}
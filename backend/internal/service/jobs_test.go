@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+// waitStatus polls the job until it reaches status or the test times out.
+func waitStatus(t *testing.T, q *jobQueue, id, status string) domain.Job {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		j, ok := q.get(id)
+		if !ok {
+			t.Fatalf("job %s not found", id)
+		}
+		if j.Status == status {
+			return j
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s is %s, want %s", id, j.Status, status)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestJobQueueDedupe(t *testing.T) {
+	q := newJobQueue()
+	release := make(chan struct{})
+	defer close(release)
+	block := func(ctx context.Context) (any, error) {
+		<-release
+		return nil, nil
+	}
+	pod := func(ns, app string) domain.Job {
+		return domain.Job{Kind: domain.JobKindPodInsight, Namespace: ns, App: app}
+	}
+
+	first := q.submit(pod("media", "jellyfin"), time.Minute, block)
+	tests := []struct {
+		name string
+		job  domain.Job
+		same bool
+	}{
+		{name: "same app", job: pod("media", "jellyfin"), same: true},
+		{name: "other app", job: pod("media", "sonarr")},
+		{name: "same name in another namespace", job: pod("web", "jellyfin")},
+		{name: "cluster", job: domain.Job{Kind: domain.JobKindClusterInsight}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := q.submit(tt.job, time.Minute, block)
+			if (got.ID == first.ID) != tt.same {
+				t.Errorf("job ID %s, first %s; want same: %v", got.ID, first.ID, tt.same)
+			}
+		})
+	}
+}
+
+func TestJobQueueConcurrentSubmits(t *testing.T) {
+	q := newJobQueue()
+	release := make(chan struct{})
+	var runs sync.WaitGroup
+	runs.Add(1)
+	run := func(ctx context.Context) (any, error) {
+		defer runs.Done()
+		<-release
+		return "ok", nil
+	}
+
+	const callers = 10
+	ids := make(chan string, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids <- q.submit(domain.Job{Kind: domain.JobKindClusterInsight}, time.Minute, run).ID
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	first := <-ids
+	for id := range ids {
+		if id != first {
+			t.Fatalf("concurrent submits got jobs %s and %s, want one", first, id)
+		}
+	}
+	close(release)
+	runs.Wait()
+}
+
+func TestJobQueueStates(t *testing.T) {
+	tests := []struct {
+		name       string
+		result     any
+		err        error
+		wantStatus string
+	}{
+		{name: "done", result: "insight", wantStatus: domain.JobDone},
+		{name: "failed", err: errors.New("overwatch: unexpected status 502"), wantStatus: domain.JobFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newJobQueue()
+			finished := make(chan domain.Job, maxConcurrentJobs+1)
+			q.onDone(func(j domain.Job) { finished <- j })
+
+			// Fill every slot so the job under test stays queued.
+			hold := make(chan struct{})
+			for i := 0; i < maxConcurrentJobs; i++ {
+				j := q.submit(domain.Job{Kind: domain.JobKindPodInsight, App: string(rune('a' + i))}, time.Minute,
+					func(ctx context.Context) (any, error) { <-hold; return nil, nil })
+				waitStatus(t, q, j.ID, domain.JobRunning)
+			}
+
+			start := make(chan struct{})
+			job := q.submit(domain.Job{Kind: domain.JobKindClusterInsight}, time.Minute,
+				func(ctx context.Context) (any, error) { <-start; return tt.result, tt.err })
+			if job.Status != domain.JobQueued {
+				t.Fatalf("new job is %s, want queued", job.Status)
+			}
+			time.Sleep(10 * time.Millisecond)
+			if j, _ := q.get(job.ID); j.Status != domain.JobQueued || j.StartedAt != nil {
+				t.Fatalf("job started with every slot busy: %+v", j)
+			}
+
+			close(hold)
+			running := waitStatus(t, q, job.ID, domain.JobRunning)
+			if running.StartedAt == nil {
+				t.Error("running job has no start time")
+			}
+			close(start)
+
+			var done domain.Job
+			for done.ID != job.ID {
+				done = <-finished
+			}
+			if done.Status != tt.wantStatus || done.FinishedAt == nil {
+				t.Errorf("finished job = %+v, want status %s with a finish time", done, tt.wantStatus)
+			}
+			if done.Result != tt.result || (tt.err != nil) != (done.Error != "") {
+				t.Errorf("result %v error %q, want %v and %v", done.Result, done.Error, tt.result, tt.err)
+			}
+			if got, _ := q.get(job.ID); got.Status != tt.wantStatus {
+				t.Errorf("stored status = %s, want %s", got.Status, tt.wantStatus)
+			}
+
+			// A finished job no longer absorbs new requests.
+			again := q.submit(domain.Job{Kind: domain.JobKindClusterInsight}, time.Minute,
+				func(ctx context.Context) (any, error) { return nil, nil })
+			if again.ID == job.ID {
+				t.Error("submit after the job finished returned the old job")
+			}
+		})
+	}
+}
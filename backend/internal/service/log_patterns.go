@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+const (
+	// patternSimilarity is the fraction of matching tokens a line needs to join
+	// an existing cluster rather than start a new one.
+	patternSimilarity = 0.5
+	// wildcardToken replaces tokens that differ between lines of the same cluster.
+	wildcardToken = "<*>"
+)
+
+var (
+	uuidPattern = regexp.MustCompile(`(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	ipPattern   = regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}(?::\d+)?\b`)
+	hexPattern  = regexp.MustCompile(`(?i)\b(?:0x)?[0-9a-f]{6,}\b`)
+	numPattern  = regexp.MustCompile(`[-+]?\d+(?:\.\d+)?`)
+)
+
+func (s *infraService) GetContainerLogPatterns(ctx context.Context, id, tail string) (*domain.ContainerLogPatterns, error) {
+	logs, err := s.cluster.GetContainerLogs(ctx, id, tail)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ContainerLogPatterns{
+		ContainerID: logs.ContainerID,
+		TotalLines:  len(logs.Lines),
+		Patterns:    clusterLogLines(logs.Lines),
+	}, nil
+}
+
+type logCluster struct {
+	tokens    []string
+	count     int
+	firstSeen *time.Time
+	lastSeen  *time.Time
+	example   string
+}
+
+// clusterLogLines groups log lines into templates in the style of the Drain
+// algorithm: variable tokens (UUIDs, IPs, hex IDs, numbers) are masked, lines
+// are bucketed by token count and first token, and lines within a bucket that
+// share enough tokens are merged, with differing positions becoming wildcards.
+func clusterLogLines(lines []string) []domain.LogPattern {
+	buckets := make(map[string][]*logCluster)
+	var clusters []*logCluster
+
+	for _, line := range lines {
+		ts, msg := splitLogTimestamp(line)
+		tokens := strings.Fields(maskLogTokens(msg))
+		if len(tokens) == 0 {
+			continue
+		}
+
+		key := bucketKey(tokens)
+		var match *logCluster
+		best := 0.0
+		for _, c := range buckets[key] {
+			if sim := tokenSimilarity(c.tokens, tokens); sim >= patternSimilarity && sim > best {
+				match, best = c, sim
+			}
+		}
+
+		if match == nil {
+			match = &logCluster{tokens: tokens, example: msg}
+			buckets[key] = append(buckets[key], match)
+			clusters = append(clusters, match)
+		} else {
+			for i, tok := range tokens {
+				if match.tokens[i] != tok {
+					match.tokens[i] = wildcardToken
+				}
+			}
+		}
+
+		match.count++
+		if ts != nil {
+			if match.firstSeen == nil || ts.Before(*match.firstSeen) {
+				match.firstSeen = ts
+			}
+			if match.lastSeen == nil || ts.After(*match.lastSeen) {
+				match.lastSeen = ts
+			}
+		}
+	}
+
+	total := 0
+	for _, c := range clusters {
+		total += c.count
+	}
+
+	patterns := make([]domain.LogPattern, 0, len(clusters))
+	for _, c := range clusters {
+		patterns = append(patterns, domain.LogPattern{
+			Pattern:   strings.Join(c.tokens, " "),
+			Count:     c.count,
+			Percent:   float64(c.count) / float64(total) * 100,
+			FirstSeen: c.firstSeen,
+			LastSeen:  c.lastSeen,
+			Example:   c.example,
+		})
+	}
+
+	sort.SliceStable(patterns, func(i, j int) bool {
+		return patterns[i].Count > patterns[j].Count
+	})
+	return patterns
+}
+
+// splitLogTimestamp strips the RFC3339 timestamp that both the kubelet and
+// Loki log paths prefix to each line.
+func splitLogTimestamp(line string) (*time.Time, string) {
+	first, rest, found := strings.Cut(line, " ")
+	if !found {
+		return nil, line
+	}
+	ts, err := time.Parse(time.RFC3339Nano, first)
+	if err != nil {
+		return nil, line
+	}
+	return &ts, rest
+}
+
+func maskLogTokens(msg string) string {
+	msg = uuidPattern.ReplaceAllString(msg, "<UUID>")
+	msg = ipPattern.ReplaceAllString(msg, "<IP>")
+	msg = hexPattern.ReplaceAllStringFunc(msg, func(tok string) string {
+		// Hex-only words ("decade", "facade") and plain numbers are left alone;
+		// an identifier needs both digits and letters.
+		if !strings.ContainsAny(tok, "0123456789") || !strings.ContainsAny(strings.ToLower(tok), "abcdef") {
+			return tok
+		}
+		return "<HEX>"
+	})
+	return numPattern.ReplaceAllString(msg, "<NUM>")
+}
+
+// bucketKey keys clusters by token count and first token. A leading wildcard
+// would scatter otherwise identical lines, so masked first tokens share a key.
+func bucketKey(tokens []string) string {
+	first := tokens[0]
+	if strings.ContainsAny(first, "<>") {
+		first = wildcardToken
+	}
+	return first + "|" + strconv.Itoa(len(tokens))
+}
+
+func tokenSimilarity(template, tokens []string) float64 {
+	if len(template) != len(tokens) {
+		return 0
+	}
+	same := 0
+	for i := range template {
+		if template[i] == tokens[i] || template[i] == wildcardToken {
+			same++
+		}
+	}
+	return float64(same) / float64(len(tokens))
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+// GetNodeMetrics returns current metrics for the cluster and every node. Nodes
+// are listed in cluster inventory order with their NodeInfo filled in; nodes
+// that only Prometheus knows about follow at the end. If the inventory can't be
+// read the metrics are returned as reported.
+func (s *infraService) GetNodeMetrics(ctx context.Context) (*domain.ClusterMetrics, error) {
+	metrics, err := s.metrics.GetNodeMetrics(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := s.cluster.ListNodes(ctx)
+	if err != nil {
+		log.Printf("[service] node metrics: list nodes: %v", err)
+		return metrics, nil
+	}
+
+	metrics.Nodes = joinNodeMetrics(nodes, metrics.Nodes)
+	return metrics, nil
+}
+
+func joinNodeMetrics(nodes []domain.NodeInfo, snapshots []domain.NodeMetrics) []domain.NodeMetrics {
+	byName := make(map[string]domain.NodeMetricsSnapshot, len(snapshots))
+	for _, snap := range snapshots {
+		byName[snap.Name] = snap.NodeMetricsSnapshot
+	}
+
+	joined := make([]domain.NodeMetrics, 0, len(nodes)+len(snapshots))
+	for _, node := range nodes {
+		joined = append(joined, domain.NodeMetrics{NodeInfo: node, NodeMetricsSnapshot: byName[node.Name]})
+		delete(byName, node.Name)
+	}
+	for _, snap := range snapshots {
+		if _, ok := byName[snap.Name]; ok {
+			joined = append(joined, snap)
+		}
+	}
+	return joined
+}
@@ -0,0 +1,356 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+	portin "github.com/isaacwallace123/portfolio-infra/internal/core/ports/in"
+	portout "github.com/isaacwallace123/portfolio-infra/internal/core/ports/out"
+)
+
+const (
+	defaultNotifyInterval = time.Minute
+	// notifySendTimeout bounds delivery to one sink.
+	notifySendTimeout = 15 * time.Second
+	crashLoopReason   = "CrashLoopBackOff"
+)
+
+// NotificationWatcher polls the service for changes worth telling someone
+// about — new high-severity anomalies, nodes going NotReady, pods entering
+// CrashLoopBackOff and newly firing alerts — and routes them to sinks.
+// Recoveries of nodes and pods are sent at low severity.
+type NotificationWatcher struct {
+	svc        portin.InfraService
+	dispatcher *notificationDispatcher
+	interval   time.Duration
+
+	nodes     map[string]string // node -> last status
+	crashing  map[string]bool   // pod ID -> in CrashLoopBackOff
+	anomalies map[string]bool   // fingerprints seen last poll
+	alerts    map[string]bool   // fingerprints seen last poll
+	lastErr   map[string]string // source -> last error logged
+}
+
+func NewNotificationWatcher(svc portin.InfraService, sinks map[string]portout.Notifier, rules []domain.NotificationRule, interval time.Duration) *NotificationWatcher {
+	if interval <= 0 {
+		interval = defaultNotifyInterval
+	}
+	return &NotificationWatcher{
+		svc:        svc,
+		dispatcher: newNotificationDispatcher(sinks, rules),
+		interval:   interval,
+		nodes:      make(map[string]string),
+		crashing:   make(map[string]bool),
+		anomalies:  make(map[string]bool),
+		alerts:     make(map[string]bool),
+		lastErr:    make(map[string]string),
+	}
+}
+
+// Run polls until ctx is cancelled.
+func (w *NotificationWatcher) Run(ctx context.Context) {
+	log.Printf("[notify] watching every %s with %d rules", w.interval, len(w.dispatcher.rules))
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *NotificationWatcher) poll(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, w.interval)
+	defer cancel()
+
+	var notes []domain.Notification
+	notes = append(notes, w.checkNodes(ctx)...)
+	notes = append(notes, w.checkPods(ctx)...)
+	notes = append(notes, w.checkAnomalies(ctx)...)
+	notes = append(notes, w.checkAlerts(ctx)...)
+	for _, n := range notes {
+		w.dispatcher.dispatch(ctx, n)
+	}
+}
+
+// failed logs a source's error once until it changes or clears, so a backend
+// that isn't configured doesn't flood the log every poll.
+func (w *NotificationWatcher) failed(source string, err error) bool {
+	if err == nil {
+		delete(w.lastErr, source)
+		return false
+	}
+	if w.lastErr[source] != err.Error() {
+		log.Printf("[notify] %s unavailable: %v", source, err)
+		w.lastErr[source] = err.Error()
+	}
+	return true
+}
+
+func (w *NotificationWatcher) checkNodes(ctx context.Context) []domain.Notification {
+	nodes, err := w.svc.ListNodes(ctx)
+	if w.failed("nodes", err) {
+		return nil
+	}
+
+	var notes []domain.Notification
+	now := time.Now()
+	for _, node := range nodes {
+		prev, known := w.nodes[node.Name]
+		w.nodes[node.Name] = node.Status
+		labels := map[string]string{"node": node.Name}
+		switch {
+		case node.Status == "NotReady" && prev != "NotReady":
+			notes = append(notes, domain.Notification{
+				Kind: domain.NotifyNodeNotReady, Key: "node/" + node.Name, Severity: domain.SeverityHigh,
+				Title:   fmt.Sprintf("Node %s is NotReady", node.Name),
+				Message: fmt.Sprintf("Kubernetes reports node %s (%s) as NotReady.", node.Name, node.Role),
+				Labels:  labels, Time: now,
+			})
+		case known && prev == "NotReady" && node.Status == "Ready":
+			notes = append(notes, domain.Notification{
+				Kind: domain.NotifyNodeNotReady, Key: "node/" + node.Name + "/resolved", Severity: domain.SeverityLow,
+				Title:   fmt.Sprintf("Node %s is Ready again", node.Name),
+				Message: fmt.Sprintf("Node %s recovered.", node.Name),
+				Labels:  labels, Time: now,
+			})
+		}
+	}
+	return notes
+}
+
+func (w *NotificationWatcher) checkPods(ctx context.Context) []domain.Notification {
+	containers, err := w.svc.ListContainers(ctx)
+	if w.failed("pods", err) {
+		return nil
+	}
+
+	var notes []domain.Notification
+	now := time.Now()
+	current := make(map[string]bool)
+	for _, c := range containers {
+		if c.Status != crashLoopReason {
+			continue
+		}
+		current[c.ID] = true
+		if w.crashing[c.ID] {
+			continue
+		}
+		namespace, _, _ := strings.Cut(c.ID, "/")
+		notes = append(notes, domain.Notification{
+			Kind: domain.NotifyCrashLoop, Key: "pod/" + c.ID, Severity: domain.SeverityHigh,
+			Title:   fmt.Sprintf("Pod %s is in CrashLoopBackOff", c.ID),
+			Message: fmt.Sprintf("A container of %s (app %s, image %s) keeps crashing.", c.ID, c.AppName, c.Image),
+			Labels:  map[string]string{"namespace": namespace, "pod": c.Name, "app": c.AppName},
+			Time:    now,
+		})
+	}
+	for id := range w.crashing {
+		if current[id] {
+			continue
+		}
+		// Only a pod that is still listed has recovered; a deleted one was replaced.
+		for _, c := range containers {
+			if c.ID == id {
+				namespace, _, _ := strings.Cut(id, "/")
+				notes = append(notes, domain.Notification{
+					Kind: domain.NotifyCrashLoop, Key: "pod/" + id + "/resolved", Severity: domain.SeverityLow,
+					Title:   fmt.Sprintf("Pod %s recovered", id),
+					Message: fmt.Sprintf("%s is no longer in CrashLoopBackOff (status %s).", id, c.Status),
+					Labels:  map[string]string{"namespace": namespace, "pod": c.Name, "app": c.AppName},
+					Time:    now,
+				})
+				break
+			}
+		}
+	}
+	w.crashing = current
+	return notes
+}
+
+func (w *NotificationWatcher) checkAnomalies(ctx context.Context) []domain.Notification {
+	insight, err := w.svc.GetOverwatchInsights(ctx)
+	if w.failed("insights", err) {
+		return nil
+	}
+
+	var notes []domain.Notification
+	now := time.Now()
+	current := make(map[string]bool)
+	for _, a := range insight.Anomalies {
+		// Acknowledged, snoozed and muted anomalies are already being handled.
+		if a.Severity != domain.SeverityHigh || a.Ack != nil {
+			continue
+		}
+		fp := a.Fingerprint
+		if fp == "" {
+			fp = domain.AnomalyFingerprint(a)
+		}
+		current[fp] = true
+		if w.anomalies[fp] {
+			continue
+		}
+		notes = append(notes, domain.Notification{
+			Kind: domain.NotifyAnomaly, Key: "anomaly/" + fp, Severity: domain.SeverityHigh,
+			Title:   fmt.Sprintf("Anomaly: %s on %s", a.Type, a.Affected),
+			Message: a.Description,
+			Labels:  map[string]string{"type": a.Type, "affected": a.Affected, "fingerprint": fp, "source": insight.Source},
+			Time:    now,
+		})
+	}
+	w.anomalies = current
+	return notes
+}
+
+func (w *NotificationWatcher) checkAlerts(ctx context.Context) []domain.Notification {
+	groups, err := w.svc.ListAlertGroups(ctx)
+	if w.failed("alerts", err) {
+		return nil
+	}
+
+	var notes []domain.Notification
+	current := make(map[string]bool)
+	for _, g := range groups {
+		for _, a := range g.Alerts {
+			if a.State != "active" || current[a.Fingerprint] {
+				continue
+			}
+			current[a.Fingerprint] = true
+			if w.alerts[a.Fingerprint] {
+				continue
+			}
+			msg := a.Summary
+			if a.Description != "" {
+				msg = strings.TrimSpace(msg + "\n" + a.Description)
+			}
+			notes = append(notes, domain.Notification{
+				Kind: domain.NotifyAlert, Key: "alert/" + a.Fingerprint, Severity: alertSeverity(a.Severity),
+				Title:   fmt.Sprintf("Alert firing: %s", a.Name),
+				Message: msg,
+				Labels:  a.Labels,
+				Time:    a.StartsAt,
+			})
+		}
+	}
+	w.alerts = current
+	return notes
+}
+
+// alertSeverity maps Prometheus alert severities onto notification ones.
+func alertSeverity(s string) string {
+	switch strings.ToLower(s) {
+	case "critical", "page", "high":
+		return domain.SeverityHigh
+	case "warning", "medium":
+		return domain.SeverityMedium
+	}
+	return domain.SeverityLow
+}
+
+// notificationDispatcher applies rules, deduplication and rate limits, then
+// delivers to sinks.
+type notificationDispatcher struct {
+	sinks map[string]portout.Notifier
+	rules []domain.NotificationRule
+	// maxWindow is the longest dedup window; older sends are forgotten.
+	maxWindow time.Duration
+
+	mu     sync.Mutex
+	sent   map[string]time.Time   // rule + key -> last send
+	recent map[string][]time.Time // rule -> sends within the last hour
+}
+
+func newNotificationDispatcher(sinks map[string]portout.Notifier, rules []domain.NotificationRule) *notificationDispatcher {
+	var maxWindow time.Duration
+	for _, r := range rules {
+		maxWindow = max(maxWindow, r.DedupWindow)
+	}
+	return &notificationDispatcher{
+		sinks:     sinks,
+		rules:     rules,
+		maxWindow: maxWindow,
+		sent:      make(map[string]time.Time),
+		recent:    make(map[string][]time.Time),
+	}
+}
+
+func (d *notificationDispatcher) dispatch(ctx context.Context, n domain.Notification) {
+	for _, rule := range d.rules {
+		if !ruleMatches(rule, n) || !d.admit(rule, n) {
+			continue
+		}
+		for _, name := range rule.Sinks {
+			sendCtx, cancel := context.WithTimeout(ctx, notifySendTimeout)
+			if err := d.sinks[name].Notify(sendCtx, n); err != nil {
+				log.Printf("[notify] rule %s: sink %s: %v", rule.Name, name, err)
+			}
+			cancel()
+		}
+	}
+}
+
+func ruleMatches(rule domain.NotificationRule, n domain.Notification) bool {
+	if len(rule.Kinds) > 0 {
+		found := false
+		for _, k := range rule.Kinds {
+			if k == n.Kind {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if rule.MinSeverity != "" && domain.SeverityRank(n.Severity) < domain.SeverityRank(rule.MinSeverity) {
+		return false
+	}
+	for k, v := range rule.Match {
+		if n.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// admit applies the rule's dedup window and hourly limit, recording the send
+// when allowed.
+func (d *notificationDispatcher) admit(rule domain.NotificationRule, n domain.Notification) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	dedupKey := rule.Name + "|" + n.Key
+	if last, ok := d.sent[dedupKey]; ok && now.Sub(last) < rule.DedupWindow {
+		return false
+	}
+
+	recent := d.recent[rule.Name][:0]
+	for _, t := range d.recent[rule.Name] {
+		if now.Sub(t) < time.Hour {
+			recent = append(recent, t)
+		}
+	}
+	if rule.MaxPerHour > 0 && len(recent) >= rule.MaxPerHour {
+		d.recent[rule.Name] = recent
+		log.Printf("[notify] rule %s: rate limit of %d/h reached, dropping %q", rule.Name, rule.MaxPerHour, n.Title)
+		return false
+	}
+
+	d.recent[rule.Name] = append(recent, now)
+	d.sent[dedupKey] = now
+	for k, t := range d.sent {
+		if now.Sub(t) > d.maxWindow {
+			delete(d.sent, k)
+		}
+	}
+	return true
+}
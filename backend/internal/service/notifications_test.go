@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+	portin "github.com/isaacwallace123/portfolio-infra/internal/core/ports/in"
+	portout "github.com/isaacwallace123/portfolio-infra/internal/core/ports/out"
+)
+
+// recordingSink keeps the keys of the notifications it is sent.
+type recordingSink struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (s *recordingSink) Notify(ctx context.Context, n domain.Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = append(s.keys, n.Key)
+	return nil
+}
+
+func (s *recordingSink) take() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := s.keys
+	s.keys = nil
+	return keys
+}
+
+func TestRuleMatches(t *testing.T) {
+	note := domain.Notification{
+		Kind: domain.NotifyCrashLoop, Severity: domain.SeverityMedium,
+		Labels: map[string]string{"namespace": "media", "app": "jellyfin"},
+	}
+	tests := []struct {
+		name string
+		rule domain.NotificationRule
+		want bool
+	}{
+		{name: "empty rule matches everything", want: true},
+		{name: "kind listed", rule: domain.NotificationRule{Kinds: []string{domain.NotifyAlert, domain.NotifyCrashLoop}}, want: true},
+		{name: "kind not listed", rule: domain.NotificationRule{Kinds: []string{domain.NotifyAnomaly}}},
+		{name: "severity at minimum", rule: domain.NotificationRule{MinSeverity: domain.SeverityMedium}, want: true},
+		{name: "severity below minimum", rule: domain.NotificationRule{MinSeverity: domain.SeverityHigh}},
+		{name: "labels match", rule: domain.NotificationRule{Match: map[string]string{"namespace": "media", "app": "jellyfin"}}, want: true},
+		{name: "label differs", rule: domain.NotificationRule{Match: map[string]string{"namespace": "web"}}},
+		{name: "label missing", rule: domain.NotificationRule{Match: map[string]string{"node": "node-1"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ruleMatches(tt.rule, note); got != tt.want {
+				t.Errorf("ruleMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatcherDedupAndRateLimit(t *testing.T) {
+	tests := []struct {
+		name string
+		rule domain.NotificationRule
+		keys []string
+		wait time.Duration // before the last key
+		want []string
+	}{
+		{
+			name: "repeat key is deduplicated",
+			rule: domain.NotificationRule{DedupWindow: time.Hour},
+			keys: []string{"a", "b", "a"},
+			want: []string{"a", "b"},
+		},
+		{
+			name: "repeat after the window is sent",
+			rule: domain.NotificationRule{DedupWindow: 20 * time.Millisecond},
+			keys: []string{"a", "a"},
+			wait: 40 * time.Millisecond,
+			want: []string{"a", "a"},
+		},
+		{
+			name: "hourly limit",
+			rule: domain.NotificationRule{DedupWindow: time.Hour, MaxPerHour: 2},
+			keys: []string{"a", "b", "c", "d"},
+			want: []string{"a", "b"},
+		},
+		{
+			name: "dropped sends don't count against the limit",
+			rule: domain.NotificationRule{DedupWindow: time.Hour, MaxPerHour: 2},
+			keys: []string{"a", "a", "a", "b"},
+			want: []string{"a", "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &recordingSink{}
+			tt.rule.Name, tt.rule.Sinks = "rule", []string{"sink"}
+			d := newNotificationDispatcher(map[string]portout.Notifier{"sink": sink}, []domain.NotificationRule{tt.rule})
+			for i, key := range tt.keys {
+				if i == len(tt.keys)-1 {
+					time.Sleep(tt.wait)
+				}
+				d.dispatch(context.Background(), domain.Notification{Key: key})
+			}
+			if got := sink.take(); !slices.Equal(got, tt.want) {
+				t.Errorf("sent %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatcherRulesAreIndependent(t *testing.T) {
+	sink := &recordingSink{}
+	rules := []domain.NotificationRule{
+		{Name: "all", Sinks: []string{"sink"}, DedupWindow: time.Hour, MaxPerHour: 1},
+		{Name: "high", Sinks: []string{"sink"}, DedupWindow: time.Hour, MinSeverity: domain.SeverityHigh},
+	}
+	d := newNotificationDispatcher(map[string]portout.Notifier{"sink": sink}, rules)
+	d.dispatch(context.Background(), domain.Notification{Key: "a", Severity: domain.SeverityHigh})
+	d.dispatch(context.Background(), domain.Notification{Key: "b", Severity: domain.SeverityHigh})
+	// "all" sends a and hits its limit; "high" sends both.
+	if got, want := sink.take(), []string{"a", "a", "b"}; !slices.Equal(got, want) {
+		t.Errorf("sent %v, want %v", got, want)
+	}
+}
+
+// watchedService is the cluster state the watcher polls.
+type watchedService struct {
+	portin.InfraService
+	nodes      []domain.NodeInfo
+	containers []domain.ContainerInfo
+	insight    domain.OverwatchInsight
+	alerts     []domain.AlertGroup
+}
+
+func (s *watchedService) ListNodes(context.Context) ([]domain.NodeInfo, error) { return s.nodes, nil }
+func (s *watchedService) ListContainers(context.Context) ([]domain.ContainerInfo, error) {
+	return s.containers, nil
+}
+func (s *watchedService) GetOverwatchInsights(context.Context) (*domain.OverwatchInsight, error) {
+	insight := s.insight
+	return &insight, nil
+}
+func (s *watchedService) ListAlertGroups(context.Context) ([]domain.AlertGroup, error) {
+	return s.alerts, nil
+}
+
+func TestNotificationWatcher(t *testing.T) {
+	svc := &watchedService{}
+	sink := &recordingSink{}
+	rule := domain.NotificationRule{Name: "all", Sinks: []string{"sink"}, DedupWindow: time.Hour}
+	w := NewNotificationWatcher(svc, map[string]portout.Notifier{"sink": sink}, []domain.NotificationRule{rule}, time.Minute)
+
+	spike := domain.OverwatchAnomaly{Type: "cpu_spike", Affected: "media/jellyfin", Severity: domain.SeverityHigh, Fingerprint: "fp1"}
+	acked := domain.OverwatchAnomaly{Type: "disk_full", Affected: "node-1", Severity: domain.SeverityHigh, Fingerprint: "fp2", Ack: &domain.AnomalyAck{}}
+	minor := domain.OverwatchAnomaly{Type: "memory_spike", Affected: "web/api", Severity: domain.SeverityMedium, Fingerprint: "fp3"}
+	firing := domain.Alert{Fingerprint: "al1", Name: "HostDown", State: "active", Severity: "critical"}
+	crashing := domain.ContainerInfo{ID: "media/jellyfin-0", Name: "jellyfin-0", Status: crashLoopReason}
+	running := crashing
+	running.Status = "Running"
+
+	steps := []struct {
+		name  string
+		setup func()
+		want  []string
+	}{
+		{
+			name: "first poll reports what is wrong",
+			setup: func() {
+				svc.nodes = []domain.NodeInfo{{Name: "node-1", Status: "NotReady"}, {Name: "node-2", Status: "Ready"}}
+				svc.containers = []domain.ContainerInfo{crashing}
+				svc.insight.Anomalies = []domain.OverwatchAnomaly{spike, acked, minor}
+				svc.alerts = []domain.AlertGroup{{Alerts: []domain.Alert{firing, firing}}}
+			},
+			want: []string{"node/node-1", "pod/media/jellyfin-0", "anomaly/fp1", "alert/al1"},
+		},
+		{name: "unchanged state is quiet", setup: func() {}},
+		{
+			name: "recoveries are reported",
+			setup: func() {
+				svc.nodes[0].Status = "Ready"
+				svc.containers = []domain.ContainerInfo{running}
+				svc.insight.Anomalies = nil
+				svc.alerts = nil
+			},
+			want: []string{"node/node-1/resolved", "pod/media/jellyfin-0/resolved"},
+		},
+		{
+			// The watcher reports the anomaly again, but the rule's dedup
+			// window holds it back.
+			name:  "a returning anomaly is deduplicated",
+			setup: func() { svc.insight.Anomalies = []domain.OverwatchAnomaly{spike} },
+		},
+	}
+	for _, step := range steps {
+		step.setup()
+		w.poll(context.Background())
+		if got := sink.take(); !slices.Equal(got, step.want) {
+			t.Errorf("%s: sent %v, want %v", step.name, got, step.want)
+		}
+	}
+}
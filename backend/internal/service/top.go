@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+// GetTopConsumers ranks pods, apps or nodes by q.By, highest first. Prometheus is
+// asked first; if it fails the cluster's metrics-server ranking is used instead.
+// Apps are ranked by summing every pod's value under its app name, so the pod
+// query runs without a limit.
+func (s *infraService) GetTopConsumers(ctx context.Context, q domain.TopQuery) (*domain.TopConsumers, error) {
+	podQuery := q
+	if q.Kind == domain.TopKindApp {
+		podQuery.Kind = domain.TopKindPod
+		podQuery.Limit = 0
+	}
+
+	top, err := s.metrics.GetTopConsumers(ctx, podQuery)
+	if err != nil {
+		log.Printf("[service] top %ss by %s: prometheus failed, using metrics-server: %v", q.Kind, q.By, err)
+		fallback, ferr := s.cluster.GetTopConsumers(ctx, podQuery)
+		if ferr != nil {
+			return nil, fmt.Errorf("%v; metrics-server fallback: %v", err, ferr)
+		}
+		top = fallback
+	}
+
+	if q.Kind == domain.TopKindApp {
+		pods, err := s.cluster.ListPodResources(ctx)
+		if err != nil {
+			return nil, err
+		}
+		top.Kind = domain.TopKindApp
+		top.Entries = groupTopByApp(top.Entries, pods)
+	}
+
+	sort.Slice(top.Entries, func(i, j int) bool {
+		a, b := top.Entries[i], top.Entries[j]
+		if a.Value != b.Value {
+			return a.Value > b.Value
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	if q.Limit > 0 && len(top.Entries) > q.Limit {
+		top.Entries = top.Entries[:q.Limit]
+	}
+	return top, nil
+}
+
+// groupTopByApp sums pod entries per namespace and app. Pods missing from the
+// inventory are dropped.
+func groupTopByApp(entries []domain.TopEntry, pods []domain.PodResources) []domain.TopEntry {
+	podApps := make(map[string]string, len(pods))
+	for _, p := range pods {
+		podApps[p.Namespace+"/"+p.Pod] = p.App
+	}
+
+	type appKey struct{ namespace, app string }
+	sums := make(map[appKey]float64)
+	for _, e := range entries {
+		app, ok := podApps[e.Namespace+"/"+e.Name]
+		if !ok {
+			continue
+		}
+		sums[appKey{e.Namespace, app}] += e.Value
+	}
+
+	grouped := make([]domain.TopEntry, 0, len(sums))
+	for key, value := range sums {
+		grouped = append(grouped, domain.TopEntry{Name: key.app, Namespace: key.namespace, Value: value})
+	}
+	return grouped
+}
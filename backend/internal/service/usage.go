@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/isaacwallace123/portfolio-infra/internal/core/domain"
+)
+
+type usageKey struct {
+	namespace string
+	app       string
+}
+
+// GetNamespaceUsage rolls pod usage, requests and limits up per namespace. When
+// rng is non-nil each group also carries CPU and memory series over the range.
+func (s *infraService) GetNamespaceUsage(ctx context.Context, rng *domain.TimeRange) (*domain.ResourceUsageReport, error) {
+	return s.aggregateUsage(ctx, rng, func(p domain.PodResources) usageKey {
+		return usageKey{namespace: p.Namespace}
+	})
+}
+
+// GetAppUsage rolls pod usage, requests and limits up per app within each
+// namespace, using the same app name resolution as the container listing.
+func (s *infraService) GetAppUsage(ctx context.Context, rng *domain.TimeRange) (*domain.ResourceUsageReport, error) {
+	return s.aggregateUsage(ctx, rng, func(p domain.PodResources) usageKey {
+		return usageKey{namespace: p.Namespace, app: p.App}
+	})
+}
+
+// aggregateUsage groups the pods in the cluster inventory by keyOf. Usage of
+// pods missing from the inventory (system namespaces, pods already deleted) is
+// left out so usage and requests always cover the same pods. Group limits only
+// sum the pods that set one.
+func (s *infraService) aggregateUsage(ctx context.Context, rng *domain.TimeRange, keyOf func(domain.PodResources) usageKey) (*domain.ResourceUsageReport, error) {
+	pods, err := s.cluster.ListPodResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.metrics.GetPodUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var usageRange *domain.PodUsageRange
+	if rng != nil {
+		if usageRange, err = s.metrics.GetPodUsageRange(ctx, *rng); err != nil {
+			return nil, err
+		}
+	}
+
+	groups := make(map[usageKey]*domain.ResourceUsage)
+	podGroups := make(map[string]usageKey, len(pods))
+	for _, p := range pods {
+		key := keyOf(p)
+		g, ok := groups[key]
+		if !ok {
+			g = &domain.ResourceUsage{Namespace: key.namespace, App: key.app}
+			groups[key] = g
+		}
+		g.Pods++
+		g.CPURequest += p.CPURequest
+		g.CPULimit += p.CPULimit
+		g.MemoryRequest += p.MemoryRequest
+		g.MemoryLimit += p.MemoryLimit
+		podGroups[p.Namespace+"/"+p.Pod] = key
+	}
+
+	for _, u := range usage.Pods {
+		if key, ok := podGroups[u.Namespace+"/"+u.Pod]; ok {
+			groups[key].CPU += u.CPU
+			groups[key].Memory += u.Memory
+		}
+	}
+
+	report := &domain.ResourceUsageReport{
+		Groups:   make([]domain.ResourceUsage, 0, len(groups)),
+		Warnings: usage.Warnings,
+		Errors:   usage.Errors,
+	}
+
+	if usageRange != nil {
+		cpu := sumSeriesByGroup(usageRange.CPU, podGroups)
+		memory := sumSeriesByGroup(usageRange.Memory, podGroups)
+		for key, g := range groups {
+			g.CPUSeries = cpu[key]
+			g.MemorySeries = memory[key]
+			if g.CPUSeries == nil {
+				g.CPUSeries = []domain.MetricPoint{}
+			}
+			if g.MemorySeries == nil {
+				g.MemorySeries = []domain.MetricPoint{}
+			}
+		}
+		report.Warnings = append(report.Warnings, usageRange.Warnings...)
+		report.Errors = append(report.Errors, usageRange.Errors...)
+	}
+
+	for _, g := range groups {
+		report.Groups = append(report.Groups, *g)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.App < b.App
+	})
+
+	return report, nil
+}
+
+// sumSeriesByGroup adds up per-pod series (labelled namespace and pod) into one
+// series per group, summing points that share a timestamp.
+func sumSeriesByGroup(series []domain.Series, podGroups map[string]usageKey) map[usageKey][]domain.MetricPoint {
+	sums := make(map[usageKey]map[int64]float64)
+	for _, s := range series {
+		key, ok := podGroups[s.Labels["namespace"]+"/"+s.Labels["pod"]]
+		if !ok {
+			continue
+		}
+		if sums[key] == nil {
+			sums[key] = make(map[int64]float64)
+		}
+		for _, pt := range s.Points {
+			sums[key][pt.Timestamp] += pt.Value
+		}
+	}
+
+	result := make(map[usageKey][]domain.MetricPoint, len(sums))
+	for key, byTime := range sums {
+		timestamps := make([]int64, 0, len(byTime))
+		for ts := range byTime {
+			timestamps = append(timestamps, ts)
+		}
+		sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+		points := make([]domain.MetricPoint, 0, len(timestamps))
+		for _, ts := range timestamps {
+			points = append(points, domain.NewMetricPoint(time.Unix(ts, 0), byTime[ts]))
+		}
+		result[key] = points
+	}
+	return result
+}
@@ -158,7 +158,7 @@ function PodDetailPanel({
 
   const chartData = prometheusRange && prometheusRange.cpu.length > 0
     ? prometheusRange.cpu.map((pt, i) => ({
-        time: pt.time,
+        time: new Date(pt.timestamp * 1000).toLocaleTimeString(),
         cpu: pt.value,
         memory: (prometheusRange.memory[i]?.value ?? 0) / 1024 / 1024,
       }))
@@ -678,7 +678,7 @@ function NodeDetailPanel({
 
   const chartData = metricsRange && metricsRange.cpu.length > 0
     ? metricsRange.cpu.map((pt, i) => ({
-        time: pt.time,
+        time: new Date(pt.timestamp * 1000).toLocaleTimeString(),
         cpu: pt.value,
         memory: metricsRange.memory[i]?.value ?? 0,
         networkRx: (metricsRange.networkRx[i]?.value ?? 0) / 1024,
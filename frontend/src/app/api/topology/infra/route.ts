@@ -8,15 +8,29 @@ const INFRA_KEY = process.env.INFRA_API_KEY || '';
 const ADMIN_ACTIONS = new Set(['networks', 'system']);
 
 // Public actions (needed by the homelab page)
-const PUBLIC_ACTIONS = new Set(['containers', 'stats', 'logs', 'metrics', 'metricsrange', 'nodemetricsrange', 'dependencies', 'nodes', 'overwatch', 'podinsights', 'allpodinsights', 'overwatchhistory']);
+const PUBLIC_ACTIONS = new Set(['containers', 'stats', 'logs', 'metrics', 'metricsrange', 'nodemetricsrange', 'dependencies', 'nodes', 'overwatch', 'podinsights', 'allpodinsights', 'overwatchhistory', 'alerts', 'job', 'entityinsights', 'overwatchdiff']);
 
-async function proxyToInfra(path: string): Promise<Response> {
+async function proxyToInfra(path: string, method = 'GET'): Promise<Response> {
   const url = `${INFRA_URL}${path}`;
   return fetch(url, {
+    method,
     headers: { 'X-API-Key': INFRA_KEY },
   });
 }
 
+// The agent marks responses served from its last good copy with these
+// headers; they are the only marker on list (array) responses.
+const STALE_HEADERS = ['X-Stale', 'Age'];
+
+function staleHeaders(response: Response): Record<string, string> {
+  const headers: Record<string, string> = {};
+  for (const name of STALE_HEADERS) {
+    const value = response.headers.get(name);
+    if (value) headers[name] = value;
+  }
+  return headers;
+}
+
 export async function GET(request: NextRequest) {
   try {
     const { searchParams } = new URL(request.url);
@@ -97,6 +111,30 @@ export async function GET(request: NextRequest) {
       case 'overwatchhistory':
         path = '/history?limit=48';
         break;
+      case 'alerts':
+        path = '/alerts';
+        break;
+      case 'overwatchdiff': {
+        const params = new URLSearchParams();
+        const from = searchParams.get('from');
+        const to = searchParams.get('to');
+        if (from) params.set('from', from);
+        if (to) params.set('to', to);
+        path = params.size > 0 ? `/overwatch/diff?${params}` : '/overwatch/diff';
+        break;
+      }
+      case 'job': {
+        const id = searchParams.get('id');
+        if (!id || !/^[0-9a-f]+$/.test(id)) return NextResponse.json({ error: 'Job ID required' }, { status: 400 });
+        path = `/jobs/${id}`;
+        break;
+      }
+      case 'entityinsights': {
+        const id = searchParams.get('id');
+        if (!id) return NextResponse.json({ error: 'Entity ID required' }, { status: 400 });
+        path = `/entities/${encodeURIComponent(id).replace(/%2F/g, '/').replace(/%3A/g, ':')}/insights`;
+        break;
+      }
       default:
         return NextResponse.json({ error: 'Invalid action' }, { status: 400 });
     }
@@ -104,7 +142,7 @@ export async function GET(request: NextRequest) {
     const response = await proxyToInfra(path);
     const data = await response.json();
 
-    return NextResponse.json(data, { status: response.status });
+    return NextResponse.json(data, { status: response.status, headers: staleHeaders(response) });
   } catch (error) {
     // If it's a redirect from requireAdmin, rethrow
     if (error && typeof error === 'object' && 'digest' in error) throw error;
@@ -116,3 +154,27 @@ export async function GET(request: NextRequest) {
     );
   }
 }
+
+// Starts a background pod analysis; poll the returned job with action=job.
+export async function POST(request: NextRequest) {
+  try {
+    const { searchParams } = new URL(request.url);
+    if (searchParams.get('action') !== 'analyzepod') {
+      return NextResponse.json({ error: 'Invalid action' }, { status: 400 });
+    }
+    const ns = searchParams.get('namespace');
+    const app = searchParams.get('app');
+    if (!ns || !app) return NextResponse.json({ error: 'namespace and app required' }, { status: 400 });
+
+    const response = await proxyToInfra(`/pod-insights?namespace=${encodeURIComponent(ns)}&app=${encodeURIComponent(app)}`, 'POST');
+    const data = await response.json();
+
+    return NextResponse.json(data, { status: response.status });
+  } catch (error) {
+    console.error('Error proxying to infra agent:', error);
+    return NextResponse.json(
+      { error: 'Infrastructure agent unavailable' },
+      { status: 503 }
+    );
+  }
+}
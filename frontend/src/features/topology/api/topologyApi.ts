@@ -11,12 +11,16 @@ import type {
   NodeInfo,
   OverwatchInsight,
   PodInsight,
+  Job,
+  AlertGroup,
   SaveTopologyDto,
 } from '../lib/types';
 import apiClient, { getErrorMessage } from '@/lib/apiClient';
 
 const BASE_URL = '/api/topology';
 const INFRA_URL = '/api/topology/infra';
+const JOB_POLL_INTERVAL_MS = 2000;
+const JOB_POLL_TIMEOUT_MS = 4 * 60 * 1000;
 
 export const topologyApi = {
   // Topology CRUD
@@ -106,9 +110,19 @@ export const topologyApi = {
     return data;
   },
 
+  // Starts an analysis job on the agent and polls it until it finishes.
   async getPodInsights(namespace: string, app: string): Promise<PodInsight> {
-    const { data } = await apiClient.get<PodInsight>(INFRA_URL, { params: { action: 'podinsights', namespace, app } });
-    return data;
+    let { data: job } = await apiClient.post<Job<PodInsight>>(INFRA_URL, null, {
+      params: { action: 'analyzepod', namespace, app },
+    });
+    const deadline = Date.now() + JOB_POLL_TIMEOUT_MS;
+    while (job.status === 'queued' || job.status === 'running') {
+      if (Date.now() > deadline) throw new Error('Analysis timed out');
+      await new Promise((resolve) => setTimeout(resolve, JOB_POLL_INTERVAL_MS));
+      ({ data: job } = await apiClient.get<Job<PodInsight>>(INFRA_URL, { params: { action: 'job', id: job.id } }));
+    }
+    if (job.status === 'failed' || !job.result) throw new Error(job.error || 'Analysis failed');
+    return job.result;
   },
 
   async getAllPodInsights(): Promise<PodInsight[]> {
@@ -120,6 +134,15 @@ export const topologyApi = {
     }
   },
 
+  async getAlerts(): Promise<AlertGroup[]> {
+    try {
+      const { data } = await apiClient.get<AlertGroup[]>(INFRA_URL, { params: { action: 'alerts' } });
+      return data;
+    } catch {
+      return [];
+    }
+  },
+
   async getOverwatchHistory(): Promise<OverwatchInsight[]> {
     try {
       const { data } = await apiClient.get<OverwatchInsight[]>(INFRA_URL, { params: { action: 'overwatchhistory' } });
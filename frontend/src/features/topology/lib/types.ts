@@ -93,10 +93,11 @@ export type SystemInfo = {
   publicIP: string;
 };
 
-export type NodeMetrics = {
+export type NodeMetricsSnapshot = {
   cpu: number | null;
   memory: number | null;
   disk: number | null;
+  load1: number | null;
   uptime: number | null;
   totalMemory: number | null;
   diskReadRate: number | null;
@@ -105,12 +106,25 @@ export type NodeMetrics = {
   networkTxRate: number | null;
 };
 
+// Set by the agent when a backend is down and it serves its last good response.
+// Set on object responses served from the agent's last good copy. Every stale
+// response, arrays included, also carries the X-Stale and Age headers.
+export type StaleMarker = {
+  stale?: boolean;
+  staleAgeSeconds?: number;
+};
+
+export type NodeMetrics = NodeMetricsSnapshot & StaleMarker & {
+  nodes: (NodeInfo & NodeMetricsSnapshot)[];
+};
+
 export type MetricPoint = {
   time: string;
+  timestamp: number;
   value: number;
 };
 
-export type MetricsRange = {
+export type MetricsRange = StaleMarker & {
   cpu: MetricPoint[];
   memory: MetricPoint[];
   disk: MetricPoint[];
@@ -118,6 +132,32 @@ export type MetricsRange = {
   networkTx: MetricPoint[];
   diskRead: MetricPoint[];
   diskWrite: MetricPoint[];
+  cpuThrottled: MetricPoint[];
+  oomEvents: MetricPoint[];
+};
+
+export type Alert = {
+  fingerprint: string;
+  name: string;
+  severity?: string;
+  state: 'active' | 'suppressed' | 'unprocessed';
+  summary?: string;
+  description?: string;
+  labels: Record<string, string>;
+  annotations: Record<string, string>;
+  startsAt: string;
+  endsAt: string;
+  generatorURL?: string;
+  silencedBy: string[];
+  inhibitedBy: string[];
+  containerId?: string;
+  node?: string;
+};
+
+export type AlertGroup = {
+  labels: Record<string, string>;
+  receiver: string;
+  alerts: Alert[];
 };
 
 // DTOs
@@ -162,6 +202,28 @@ export type PodInsight = {
   diagnosis: string;
   root_cause: string;
   suggestions: string[];
+  entity?: string;
+};
+
+export type Job<T> = {
+  id: string;
+  kind: 'pod-insight' | 'cluster-insight';
+  namespace?: string;
+  app?: string;
+  status: 'queued' | 'running' | 'done' | 'failed';
+  createdAt: string;
+  startedAt?: string;
+  finishedAt?: string;
+  result?: T;
+  error?: string;
+};
+
+export type AnomalyAck = {
+  fingerprint: string;
+  state: 'acknowledged' | 'snoozed' | 'muted';
+  until?: string;
+  note?: string;
+  created_at: string;
 };
 
 export type OverwatchAnomaly = {
@@ -169,15 +231,51 @@ export type OverwatchAnomaly = {
   type: string;
   description: string;
   affected: string;
+  fingerprint?: string;
+  ack?: AnomalyAck;
+  hidden?: boolean;
+  entities?: string[];
 };
 
-export type OverwatchInsight = {
+export type OverwatchInsight = StaleMarker & {
   id?: number;
   collected_at: string | null;
   status: 'healthy' | 'warning' | 'critical' | 'unknown' | 'pending';
   summary: string;
   anomalies: OverwatchAnomaly[];
   recommendations: string[];
+  source?: 'overwatch' | 'local' | 'rules';
+  hidden_anomalies?: number;
+};
+
+export type InsightSnapshot = {
+  collected_at: string | null;
+  status: OverwatchInsight['status'];
+  summary: string;
+  source?: 'overwatch' | 'local' | 'rules';
+};
+
+export type InsightDiff = {
+  from: InsightSnapshot | null;
+  to: InsightSnapshot;
+  status_changed: boolean;
+  added: OverwatchAnomaly[];
+  resolved: OverwatchAnomaly[];
+  persisting: OverwatchAnomaly[];
+  recommendations_added: string[];
+  recommendations_removed: string[];
+};
+
+export type EntityInsights = {
+  id: string;
+  kind: 'cluster' | 'node' | 'app' | 'pod';
+  related: string[];
+  upstream: string[];
+  downstream: string[];
+  anomalies: (OverwatchAnomaly & { collected_at?: string; source?: string; via?: string })[];
+  podInsights: (PodInsight & { via?: string })[];
+  alerts: (Alert & { via?: string })[];
+  errors?: string[];
 };
 
 export type AppDependency = {
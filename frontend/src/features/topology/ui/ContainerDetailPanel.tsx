@@ -6,10 +6,10 @@ import { Badge } from '@/components/ui/badge';
 import { Button } from '@/components/ui/button';
 import { Progress } from '@/components/ui/progress';
 import { ScrollArea } from '@/components/ui/scroll-area';
-import { X, Activity, HardDrive, Cpu, MemoryStick, Clock, RefreshCw } from 'lucide-react';
+import { X, Activity, HardDrive, Cpu, MemoryStick, Clock, RefreshCw, AlertTriangle } from 'lucide-react';
 import { topologyApi } from '../api/topologyApi';
 import { getLogLineClassName, splitTimestamp, detectLogLevel } from '../lib/logColorizer';
-import type { ContainerInfo, ContainerStats, NodeMetrics } from '../lib/types';
+import type { Alert, ContainerInfo, ContainerStats, NodeMetrics } from '../lib/types';
 
 interface ContainerDetailPanelProps {
   container: ContainerInfo | null;
@@ -47,6 +47,7 @@ export function ContainerDetailPanel({ container, onClose, t }: ContainerDetailP
   const [stats, setStats] = useState<ContainerStats | null>(null);
   const [logs, setLogs] = useState<string[]>([]);
   const [nodeMetrics, setNodeMetrics] = useState<NodeMetrics | null>(null);
+  const [alerts, setAlerts] = useState<Alert[]>([]);
   const [statsLoading, setStatsLoading] = useState(false);
   const [logsLoading, setLogsLoading] = useState(false);
   const logsEndRef = useRef<HTMLDivElement>(null);
@@ -80,12 +81,23 @@ export function ContainerDetailPanel({ container, onClose, t }: ContainerDetailP
     }
   }, []);
 
+  // Firing alerts whose namespace/pod labels point at this container
+  const fetchAlerts = useCallback(async (id: string) => {
+    const groups = await topologyApi.getAlerts();
+    setAlerts(
+      groups
+        .flatMap((g) => g.alerts)
+        .filter((a) => a.containerId === id && a.state === 'active'),
+    );
+  }, []);
+
   // Fetch data when container changes — use container.name
   useEffect(() => {
     if (!container) return;
 
     setStats(null);
     setLogs([]);
+    setAlerts([]);
     setStatsLoading(true);
     setLogsLoading(true);
 
@@ -93,6 +105,7 @@ export function ContainerDetailPanel({ container, onClose, t }: ContainerDetailP
       fetchStats(container.name),
       fetchLogs(container.name),
       fetchMetrics(),
+      fetchAlerts(container.id),
     ]).finally(() => {
       setStatsLoading(false);
       setLogsLoading(false);
@@ -106,7 +119,7 @@ export function ContainerDetailPanel({ container, onClose, t }: ContainerDetailP
     return () => {
       if (intervalRef.current) clearInterval(intervalRef.current);
     };
-  }, [container, fetchStats, fetchLogs, fetchMetrics]);
+  }, [container, fetchStats, fetchLogs, fetchMetrics, fetchAlerts]);
 
   // Auto-scroll logs
   useEffect(() => {
@@ -144,6 +157,25 @@ export function ContainerDetailPanel({ container, onClose, t }: ContainerDetailP
             )}
           </div>
 
+          {/* Firing alerts */}
+          {alerts.length > 0 && (
+            <div className="space-y-1.5">
+              {alerts.map((alert) => (
+                <div
+                  key={alert.fingerprint}
+                  className="flex items-start gap-2 rounded-md border border-red-500/30 bg-red-500/10 px-2.5 py-1.5 text-xs text-red-600"
+                >
+                  <AlertTriangle className="h-3.5 w-3.5 shrink-0 mt-0.5" />
+                  <div className="min-w-0">
+                    <span className="font-medium">{alert.name}</span>
+                    {alert.severity && <span className="ml-1.5 opacity-75">({alert.severity})</span>}
+                    {alert.summary && <p className="text-red-600/80 truncate">{alert.summary}</p>}
+                  </div>
+                </div>
+              ))}
+            </div>
+          )}
+
           {/* Container Info */}
           <Card>
             <CardHeader className="pb-2">